@@ -0,0 +1,216 @@
+// Command atlas packs a directory of PNG frames into a texture atlas, and writes sprite sheet metadata readable by loader.LoadSpriteSheets.
+//
+// Usage:
+//
+//	atlas [flags] FRAME_DIRECTORY
+//
+// Frames are sorted by file name, which determines their sprite numbers.
+// With the -animations flag, frames whose file name matches the animation pattern (by default a name followed by a frame number,
+// for example walk_01.png) are grouped into animations, ordered by frame number.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	name := flag.String("name", "", "sprite sheet name (default is the frame directory name)")
+	output := flag.String("output", "atlas.png", "output texture image file")
+	metadata := flag.String("metadata", "spritesheets.toml", "output sprite sheet metadata file")
+	padding := flag.Int("padding", 1, "padding in pixels between frames")
+	maxWidth := flag.Int("max-width", 2048, "maximum width of the texture image")
+	animations := flag.Bool("animations", false, "group frames into animations by file name")
+	pattern := flag.String("pattern", `^(.+?)[_-]?(\d+)$`, "regular expression matching frame names, with animation name and frame number groups")
+	frameTime := flag.Float64("frame-time", 0.1, "duration of each animation frame in seconds")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *padding < 0 {
+		fmt.Fprintf(os.Stderr, "invalid padding: %d, must not be negative\n", *padding)
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *frameTime <= 0 {
+		fmt.Fprintf(os.Stderr, "invalid frame time: %v, must be positive\n", *frameTime)
+		flag.Usage()
+		os.Exit(2)
+	}
+	frameDirectory := flag.Arg(0)
+	if *name == "" {
+		*name = filepath.Base(filepath.Clean(frameDirectory))
+	}
+	animationPattern, err := regexp.Compile(*pattern)
+	if err != nil {
+		log.Fatalf("invalid animation pattern: %v", err)
+	}
+
+	frames := loadFrames(frameDirectory)
+	width, height := pack(frames, *padding, *maxWidth)
+
+	// Write texture image
+	atlas := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for _, frame := range frames {
+		bounds := frame.Image.Bounds()
+		draw.Draw(atlas, image.Rect(frame.X, frame.Y, frame.X+bounds.Dx(), frame.Y+bounds.Dy()), frame.Image, bounds.Min, draw.Src)
+	}
+	for _, path := range []string{*output, *metadata} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			log.Fatal(err)
+		}
+	}
+	outputFile, err := os.Create(*output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := png.Encode(outputFile, atlas); err != nil {
+		log.Fatal(err)
+	}
+	if err := outputFile.Close(); err != nil {
+		log.Fatal(err)
+	}
+
+	// Write metadata, with a texture image path relative to the metadata file
+	texturePath, err := filepath.Rel(filepath.Dir(*metadata), *output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var animationList []animation
+	if *animations {
+		animationList = groupAnimations(frames, animationPattern, *frameTime)
+	}
+	content := formatSpriteSheet(*name, filepath.ToSlash(texturePath), frames, animationList)
+	if err := os.WriteFile(*metadata, []byte(content), 0644); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("packed %v frames into %s (%vx%v)\n", len(frames), *output, width, height)
+}
+
+// Load PNG frames from a directory, sorted by file name
+func loadFrames(frameDirectory string) []*Frame {
+	paths, err := filepath.Glob(filepath.Join(frameDirectory, "*.png"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(paths) == 0 {
+		log.Fatalf("no PNG frame found in directory '%s'", frameDirectory)
+	}
+	sort.Strings(paths)
+
+	frames := make([]*Frame, len(paths))
+	for iPath, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		frameImage, err := png.Decode(file)
+		file.Close()
+		if err != nil {
+			log.Fatalf("unable to decode frame '%s': %v", path, err)
+		}
+		frames[iPath] = &Frame{Name: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), Image: frameImage}
+	}
+	return frames
+}
+
+type animation struct {
+	name          string
+	time          []float64
+	spriteNumbers []int
+}
+
+// Group frames into animations by frame name, ordered by frame number
+func groupAnimations(frames []*Frame, pattern *regexp.Regexp, frameTime float64) []animation {
+	type animationFrame struct {
+		number       int
+		spriteNumber int
+	}
+	animationFrames := make(map[string][]animationFrame)
+	for iFrame, frame := range frames {
+		match := pattern.FindStringSubmatch(frame.Name)
+		if len(match) != 3 {
+			continue
+		}
+		number, err := strconv.Atoi(match[2])
+		if err != nil {
+			log.Fatalf("invalid frame number in frame '%s': %v", frame.Name, err)
+		}
+		animationFrames[match[1]] = append(animationFrames[match[1]], animationFrame{number, iFrame})
+	}
+
+	names := make([]string, 0, len(animationFrames))
+	for name := range animationFrames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	animations := make([]animation, len(names))
+	for iName, name := range names {
+		frames := animationFrames[name]
+		sort.SliceStable(frames, func(i, j int) bool { return frames[i].number < frames[j].number })
+
+		animations[iName] = animation{name: name, time: make([]float64, len(frames)+1), spriteNumbers: make([]int, len(frames))}
+		for iFrame, frame := range frames {
+			animations[iName].spriteNumbers[iFrame] = frame.spriteNumber
+			// Round times to avoid floating-point artifacts in the metadata file
+			animations[iName].time[iFrame+1] = math.Round(float64(iFrame+1)*frameTime*1e6) / 1e6
+		}
+	}
+	return animations
+}
+
+var bareKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Quote a TOML key if necessary
+func formatKey(key string) string {
+	if bareKeyRegexp.MatchString(key) {
+		return key
+	}
+	return strconv.Quote(key)
+}
+
+// Format sprite sheet metadata in the TOML format used by loader.LoadSpriteSheets
+func formatSpriteSheet(name string, texturePath string, frames []*Frame, animations []animation) string {
+	var content strings.Builder
+	fmt.Fprintf(&content, "[sprite_sheet.%s]\n", formatKey(name))
+	fmt.Fprintf(&content, "texture_image = %s\n", strconv.Quote(texturePath))
+	content.WriteString("sprites = [\n")
+	for iFrame, frame := range frames {
+		fmt.Fprintf(&content, "    { x = %v, y = %v, width = %v, height = %v }, # Sprite %v: %s\n", frame.X, frame.Y, frame.Width(), frame.Height(), iFrame, frame.Name)
+	}
+	content.WriteString("]\n")
+
+	for _, animation := range animations {
+		times := make([]string, len(animation.time))
+		for iTime, time := range animation.time {
+			times[iTime] = strconv.FormatFloat(time, 'f', -1, 64)
+			if !strings.Contains(times[iTime], ".") {
+				times[iTime] += ".0"
+			}
+		}
+		spriteNumbers := make([]string, len(animation.spriteNumbers))
+		for iSpriteNumber, spriteNumber := range animation.spriteNumbers {
+			spriteNumbers[iSpriteNumber] = strconv.Itoa(spriteNumber)
+		}
+
+		fmt.Fprintf(&content, "\n[sprite_sheet.%s.animations.%s]\n", formatKey(name), formatKey(animation.name))
+		fmt.Fprintf(&content, "time = [%s]\n", strings.Join(times, ", "))
+		fmt.Fprintf(&content, "sprite_number = [%s]\n", strings.Join(spriteNumbers, ", "))
+	}
+	return content.String()
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"image"
+	"sort"
+)
+
+// Frame is a sprite image placed in the atlas
+type Frame struct {
+	// Frame file name, without extension
+	Name  string
+	Image image.Image
+	// Position of the frame in the atlas
+	X int
+	Y int
+}
+
+// Width returns the frame width
+func (f *Frame) Width() int {
+	return f.Image.Bounds().Dx()
+}
+
+// Height returns the frame height
+func (f *Frame) Height() int {
+	return f.Image.Bounds().Dy()
+}
+
+// Pack frames in rows of decreasing height, and return the atlas dimensions.
+// Frames are separated by padding pixels, and rows are not wider than the maximum width if possible.
+func pack(frames []*Frame, padding int, maxWidth int) (int, int) {
+	sorted := make([]*Frame, len(frames))
+	copy(sorted, frames)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Height() > sorted[j].Height()
+	})
+
+	width, height := 0, 0
+	x, y, rowHeight := 0, 0, 0
+	for _, frame := range sorted {
+		// Start a new row
+		if x > 0 && x+frame.Width() > maxWidth {
+			x = 0
+			y += rowHeight + padding
+			rowHeight = 0
+		}
+
+		frame.X, frame.Y = x, y
+		x += frame.Width() + padding
+
+		if frame.Height() > rowHeight {
+			rowHeight = frame.Height()
+		}
+		if frame.X+frame.Width() > width {
+			width = frame.X + frame.Width()
+		}
+		if frame.Y+frame.Height() > height {
+			height = frame.Y + frame.Height()
+		}
+	}
+	return width, height
+}
@@ -0,0 +1,67 @@
+// Command validate checks metadata files of a game, and reports problems with their file, line and key path.
+//
+// Game components are not registered in the command, so they are only accepted by name with the -component flag,
+// and their fields are not checked. Games can check them by calling loader.Validate with ValidateConfig.Components.
+//
+// Usage:
+//
+//	validate [flags]
+//
+// Paths are relative to the working directory, and flags can be repeated for validating several files:
+//
+//	validate -spritesheets metadata/spritesheets.toml -fonts metadata/fonts.toml -entities metadata/start.toml -component Gopher
+package main
+
+import (
+	"flag"
+	"fmt"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"strings"
+
+	"github.com/x-hgg-x/goecsengine/loader"
+)
+
+// List of values for a repeated flag
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func main() {
+	var spriteSheets, fonts, controls, entities, gameComponents listFlag
+	flag.Var(&spriteSheets, "spritesheets", "sprite sheet metadata file")
+	flag.Var(&fonts, "fonts", "font metadata file")
+	flag.Var(&controls, "controls", "controls config file")
+	flag.Var(&entities, "entities", "entity metadata file")
+	flag.Var(&gameComponents, "component", "name of a game component accepted in entity files, without checking its fields")
+	flag.Parse()
+
+	if flag.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "unexpected arguments: %s\n", strings.Join(flag.Args(), " "))
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	problems := loader.Validate(loader.OSFS{}, loader.ValidateConfig{
+		SpriteSheets:   spriteSheets,
+		Fonts:          fonts,
+		Controls:       controls,
+		Entities:       entities,
+		GameComponents: gameComponents,
+	})
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+}
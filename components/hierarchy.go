@@ -0,0 +1,30 @@
+package components
+
+import (
+	"math"
+
+	ecs "github.com/x-hgg-x/goecs/v2"
+)
+
+// Parent component links an entity to its parent entity.
+// The Transform component of a child entity is relative to the global transform of its parent.
+// The global transform of a child entity is computed by the hierarchy system and stored in the GlobalTransform component.
+type Parent struct {
+	Entity ecs.Entity
+}
+
+// Compose returns the global transform of a child entity from the global transform of its parent and the local transform of the child.
+// Origin is inherited from the parent, and depth is an offset from the parent depth.
+func (t *Transform) Compose(local *Transform) *Transform {
+	scaleX, scaleY := t.Scale1.X+1, t.Scale1.Y+1
+	localX, localY := local.Translation.X*scaleX, local.Translation.Y*scaleY
+	sin, cos := math.Sincos(t.Rotation)
+
+	global := NewTransform()
+	global.SetScale(scaleX*(local.Scale1.X+1), scaleY*(local.Scale1.Y+1))
+	global.SetRotation(t.Rotation + local.Rotation)
+	global.SetTranslation(t.Translation.X+localX*cos-localY*sin, t.Translation.Y+localX*sin+localY*cos)
+	global.SetOrigin(t.Origin)
+	global.SetDepth(t.Depth + local.Depth)
+	return global
+}
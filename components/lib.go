@@ -14,17 +14,31 @@ type EngineComponents struct {
 	Text             *ecs.SliceComponent
 	UITransform      *ecs.SliceComponent
 	MouseReactive    *ecs.SliceComponent
+	Owner            *ecs.SliceComponent
+	Parent           *ecs.SliceComponent
+	GlobalTransform  *ecs.SliceComponent
+	Name             *ecs.SliceComponent
+	Tags             *ecs.SliceComponent
+}
+
+// Owner component contains the state which was running when the entity was created
+type Owner struct {
+	State interface{}
+	// ID is unique for each started state, so that a state started several times owns different entities
+	ID int
 }
 
 // Components contains engine and game components
 type Components struct {
 	Engine *EngineComponents
 	Game   interface{}
+
+	registry map[string]RegisteredComponent
 }
 
 // InitComponents initializes components
 func InitComponents(manager *ecs.Manager, gameComponents interface{}) *Components {
-	components := &Components{Engine: &EngineComponents{}, Game: gameComponents}
+	components := &Components{Engine: &EngineComponents{}, Game: gameComponents, registry: make(map[string]RegisteredComponent)}
 	initFields(manager, components.Engine)
 	initFields(manager, components.Game)
 	return components
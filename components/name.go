@@ -0,0 +1,21 @@
+package components
+
+// Name component contains the name of an entity, used for finding the entity with World.FindByName
+type Name struct {
+	Name string
+}
+
+// Tags component contains the tags of an entity, used for finding entities with World.FindByTag
+type Tags struct {
+	Tags []string
+}
+
+// HasTag checks if the tag list contains the specified tag
+func (t *Tags) HasTag(tag string) bool {
+	for _, t := range t.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
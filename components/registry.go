@@ -0,0 +1,55 @@
+package components
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/x-hgg-x/goecsengine/utils"
+
+	ecs "github.com/x-hgg-x/goecs/v2"
+)
+
+// RegisteredComponent is a game component registered by name
+type RegisteredComponent struct {
+	// Component name, used as the component table name in TOML files
+	Name string
+	// Component data type
+	Type reflect.Type
+	// ECS component storing the data
+	Component ecs.DataComponent
+}
+
+// Register registers a game component by name, allowing it to be loaded from TOML files
+func Register[T any](components *Components, name string, component ecs.DataComponent) {
+	if _, ok := reflect.TypeOf(EngineComponents{}).FieldByName(name); ok {
+		utils.LogFatalf("component name '%s' is already used by an engine component", name)
+	}
+	if _, ok := components.registry[name]; ok {
+		utils.LogFatalf("component name '%s' is already registered", name)
+	}
+	if component == nil || reflect.ValueOf(component).IsNil() {
+		utils.LogFatalf("unable to register component '%s': ECS component is not initialized", name)
+	}
+
+	components.registry[name] = RegisteredComponent{
+		Name:      name,
+		Type:      reflect.TypeOf((*T)(nil)).Elem(),
+		Component: component,
+	}
+}
+
+// Registered returns the registered game component with the specified name
+func (c *Components) Registered(name string) (RegisteredComponent, bool) {
+	registered, ok := c.registry[name]
+	return registered, ok
+}
+
+// RegisteredNames returns the sorted names of all registered game components
+func (c *Components) RegisteredNames() []string {
+	names := make([]string, 0, len(c.registry))
+	for name := range c.registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -2,12 +2,12 @@ package components
 
 import (
 	"fmt"
+	"image/color"
 
 	"github.com/x-hgg-x/goecsengine/math"
 	"github.com/x-hgg-x/goecsengine/utils"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 )
 
 // Sprite structure
@@ -22,23 +22,82 @@ type Sprite struct {
 	Height int
 }
 
+// SpriteGrid structure, used for declaring the sprites of a regular grid
+type SpriteGrid struct {
+	// Width of a grid cell
+	CellWidth int `toml:"cell_width"`
+	// Height of a grid cell
+	CellHeight int `toml:"cell_height"`
+	// Number of rows of the grid
+	Rows int
+	// Number of columns of the grid
+	Columns int
+	// Space between the texture borders and the grid cells
+	Margin int
+	// Space between grid cells
+	Spacing int
+	// Number of sprites in the grid, in row-major order. Default is the number of cells.
+	Count int
+}
+
+// Sprites returns the sprites of the grid cells, in row-major order
+func (g *SpriteGrid) Sprites() ([]Sprite, error) {
+	if g.CellWidth <= 0 || g.CellHeight <= 0 {
+		return nil, fmt.Errorf("grid cell dimensions must be positive")
+	}
+	if g.Rows <= 0 || g.Columns <= 0 {
+		return nil, fmt.Errorf("grid rows and columns must be positive")
+	}
+	if g.Margin < 0 || g.Spacing < 0 {
+		return nil, fmt.Errorf("grid margin and spacing must not be negative")
+	}
+
+	count := g.Count
+	if count == 0 {
+		count = g.Rows * g.Columns
+	}
+	if count < 0 || count > g.Rows*g.Columns {
+		return nil, fmt.Errorf("grid count %v is out of range for %vx%v cells", g.Count, g.Columns, g.Rows)
+	}
+
+	sprites := make([]Sprite, count)
+	for iSprite := range sprites {
+		sprites[iSprite] = Sprite{
+			X:      g.Margin + (iSprite%g.Columns)*(g.CellWidth+g.Spacing),
+			Y:      g.Margin + (iSprite/g.Columns)*(g.CellHeight+g.Spacing),
+			Width:  g.CellWidth,
+			Height: g.CellHeight,
+		}
+	}
+	return sprites, nil
+}
+
 // Texture structure
 type Texture struct {
 	// Texture image
 	Image *ebiten.Image
+	// Texture image path in the file system used for loading the sprite sheet.
+	// It is relative to the sprite sheet file when decoded, and resolved by the sprite sheet loader.
+	Path string
 }
 
-// UnmarshalText fills structure fields from text data
+// UnmarshalText fills structure fields from text data.
+// The texture image is loaded from its path by the sprite sheet loader.
 func (t *Texture) UnmarshalText(text []byte) error {
-	textureImage, _ := utils.Try2(ebitenutil.NewImageFromFile(string(text)))
-	t.Image = textureImage
+	t.Path = string(text)
 	return nil
 }
 
 // SpriteSheet structure
 type SpriteSheet struct {
+	// Sprite sheet name, set when loaded from a sprite sheet file
+	Name string `toml:"-"`
+	// Fill color, set when generated from a colored rectangle
+	FillColor *color.RGBA `toml:"-"`
 	// Texture image
 	Texture Texture `toml:"texture_image"`
+	// Grid of sprites, whose sprites are placed before the listed sprites when loaded from a sprite sheet file
+	Grid *SpriteGrid
 	// List of sprites
 	Sprites []Sprite
 	// List of animations
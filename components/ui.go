@@ -10,12 +10,25 @@ import (
 	"golang.org/x/image/font"
 )
 
+// FontFaceOptions contains options used for creating a font face
+type FontFaceOptions struct {
+	Size              float64
+	DPI               float64
+	Hinting           string
+	GlyphCacheEntries int `toml:"glyph_cache_entries"`
+	SubPixelsX        int `toml:"sub_pixels_x"`
+	SubPixelsY        int `toml:"sub_pixels_y"`
+}
+
 // Text component
 type Text struct {
 	ID       string
 	Text     string
 	FontFace font.Face
 	Color    color.RGBA
+	// Font name and options used for creating the font face, set when loaded from a TOML file
+	Font            string
+	FontFaceOptions FontFaceOptions
 }
 
 // Pivot variants
@@ -53,6 +66,20 @@ type UITransform struct {
 	Origin string
 	// Pivot defines the position of the element relative to its translation (default is Middle).
 	Pivot string
+	// Cached dot offset
+	dotOffset         math.VectorInt2
+	dotOffsetComputed bool
+}
+
+// GetDotOffset returns the cached dot offset, and if it has been computed
+func (t *UITransform) GetDotOffset() (x, y int, ok bool) {
+	return t.dotOffset.X, t.dotOffset.Y, t.dotOffsetComputed
+}
+
+// SetDotOffset sets the cached dot offset
+func (t *UITransform) SetDotOffset(x, y int) {
+	t.dotOffset = math.VectorInt2{X: x, Y: y}
+	t.dotOffsetComputed = true
 }
 
 // ComputeOriginOffset returns the UI transform origin offset
@@ -86,9 +113,10 @@ func (t *UITransform) ComputeOriginOffset(screenWidth, screenHeight int) (offset
 
 // MouseReactive component
 type MouseReactive struct {
-	ID          string
-	Hovered     bool
-	JustClicked bool
+	ID string
+	// State updated by the UI system, which is not loaded or saved
+	Hovered     bool `toml:"-"`
+	JustClicked bool `toml:"-"`
 }
 
 // ComputeDotOffset computes dot offset from text and pivot
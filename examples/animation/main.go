@@ -2,6 +2,9 @@ package main
 
 import (
 	_ "image/png"
+	"log"
+	"os"
+	"time"
 
 	"github.com/x-hgg-x/goecsengine/loader"
 	r "github.com/x-hgg-x/goecsengine/resources"
@@ -17,9 +20,14 @@ const (
 	gameHeight = 600
 )
 
+// Files are read from the examples directory, which contains the assets shared by all examples.
+// Metadata files reference assets with paths relative to this directory, such as "/assets/textures/gopher.png".
+var assets = os.DirFS("..")
+
 type mainGame struct {
 	world        w.World
 	stateMachine s.StateMachine
+	watcher      *loader.Watcher
 }
 
 func (game *mainGame) Layout(outsideWidth, outsideHeight int) (int, int) {
@@ -27,8 +35,11 @@ func (game *mainGame) Layout(outsideWidth, outsideHeight int) (int, int) {
 }
 
 func (game *mainGame) Update() error {
-	game.stateMachine.Update(game.world)
-	return nil
+	// Reload modified metadata files
+	if err := game.watcher.Update(game.world); err != nil {
+		log.Println(err)
+	}
+	return game.stateMachine.Update(game.world)
 }
 
 func (game *mainGame) Draw(screen *ebiten.Image) {
@@ -38,6 +49,10 @@ func (game *mainGame) Draw(screen *ebiten.Image) {
 func main() {
 	world := w.InitWorld(nil)
 
+	// Watch metadata files for hot reload
+	watcher := loader.NewWatcher(assets, time.Second)
+	r.Insert(world.Resources, watcher)
+
 	// Init screen dimensions
 	world.Resources.ScreenDimensions = &r.ScreenDimensions{Width: gameWidth, Height: gameHeight}
 
@@ -47,21 +62,23 @@ func main() {
 		StepBackwardAction, StepForwardAction, HalfSpeedAction, DoubleSpeedAction, StartPauseAction,
 		RestartAction, ReverseAction, SetTimeToMiddleAction, AbortAction, ResetAction,
 	}
-	controls, inputHandler := loader.LoadControls("config/controls.toml", axes, actions)
+	controls, inputHandler := watcher.LoadControls("animation/config/controls.toml", axes, actions)
 	world.Resources.Controls = &controls
 	world.Resources.InputHandler = &inputHandler
 
 	// Load sprite sheets
-	spriteSheets := loader.LoadSpriteSheets("metadata/spritesheets.toml")
+	spriteSheets := watcher.LoadSpriteSheets("animation/metadata/spritesheets.toml")
 	world.Resources.SpriteSheets = &spriteSheets
 
 	// Load fonts
-	fonts := loader.LoadFonts("metadata/fonts.toml")
+	fonts := watcher.LoadFonts("animation/metadata/fonts.toml")
 	world.Resources.Fonts = &fonts
 
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
 	ebiten.SetWindowSize(gameWidth, gameHeight)
 	ebiten.SetWindowTitle("Demo")
 
-	utils.LogError(ebiten.RunGame(&mainGame{world, s.Init(&GameplayState{}, world)}))
+	if err := ebiten.RunGame(&mainGame{world, s.Init(&GameplayState{}, world), watcher}); err != s.ErrQuit {
+		utils.LogError(err)
+	}
 }
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"github.com/x-hgg-x/goecsengine/loader"
+	r "github.com/x-hgg-x/goecsengine/resources"
 	"github.com/x-hgg-x/goecsengine/states"
+	"github.com/x-hgg-x/goecsengine/utils"
 	w "github.com/x-hgg-x/goecsengine/world"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -19,14 +22,12 @@ func (st *GameplayState) OnResume(world w.World) {}
 
 // OnStart method
 func (st *GameplayState) OnStart(world w.World) {
-	LoadEntities("metadata/game.toml", world)
-	LoadEntities("metadata/text.toml", world)
+	utils.Try(r.Get[*loader.Watcher](world.Resources)).LoadEntities("animation/metadata/game.toml", world)
+	utils.Try(r.Get[*loader.Watcher](world.Resources)).LoadEntities("animation/metadata/text.toml", world)
 }
 
 // OnStop method
-func (st *GameplayState) OnStop(world w.World) {
-	world.Manager.DeleteAllEntities()
-}
+func (st *GameplayState) OnStop(world w.World) {}
 
 // Update method
 func (st *GameplayState) Update(world w.World) states.Transition {
@@ -4,6 +4,9 @@ import (
 	"fmt"
 
 	c "github.com/x-hgg-x/goecsengine/components"
+	"github.com/x-hgg-x/goecsengine/loader"
+	r "github.com/x-hgg-x/goecsengine/resources"
+	"github.com/x-hgg-x/goecsengine/utils"
 	w "github.com/x-hgg-x/goecsengine/world"
 
 	ecs "github.com/x-hgg-x/goecs/v2"
@@ -116,22 +119,26 @@ func DemoSystem(world w.World) {
 	}))
 
 	// Update text info
-	currentBat := 0
-	aborted := world.Manager.Join(world.Components.Engine.AnimationControl).Empty()
-	world.Manager.Join(world.Components.Engine.Text, world.Components.Engine.UITransform).Visit(ecs.Visit(func(entity ecs.Entity) {
-		text := world.Components.Engine.Text.Get(entity).(*c.Text)
-		if text.ID == "last_command" && lastAction != "" {
-			text.Text = fmt.Sprintf("Last command: %s", lastAction)
-		}
-		if text.ID == "aborted" && aborted {
-			text.Text = "Aborted: true (Press Enter to reset)"
-		}
-		if text.ID == "rate_multiplier" && rateMultiplier != 0 {
-			text.Text = fmt.Sprintf("Rate multiplier: %v", rateMultiplier)
+	setText := func(name string, text string) {
+		if entity, ok := world.FindByName(name); ok {
+			world.GetMut(entity, world.Components.Engine.Text).(*c.Text).Text = text
 		}
-		if text.ID == fmt.Sprintf("bat%v", currentBat) && !aborted {
+	}
+
+	aborted := world.Manager.Join(world.Components.Engine.AnimationControl).Empty()
+	if lastAction != "" {
+		setText("last_command", fmt.Sprintf("Last command: %s", lastAction))
+	}
+	if aborted {
+		setText("aborted", "Aborted: true (Press Enter to reset)")
+	}
+	if rateMultiplier != 0 {
+		setText("rate_multiplier", fmt.Sprintf("Rate multiplier: %v", rateMultiplier))
+	}
+	if !aborted {
+		for iBat := range animationStates {
 			state := ""
-			switch animationStates[currentBat].Type {
+			switch animationStates[iBat].Type {
 			case c.ControlStateNotStarted:
 				state = "NotStarted"
 			case c.ControlStateRunning:
@@ -141,15 +148,14 @@ func DemoSystem(world w.World) {
 			case c.ControlStateDone:
 				state = "Done"
 			}
-			text.Text = fmt.Sprintf("Time: %.2f / Sprite: %v / %s", animationStates[currentBat].CurrentTime, spriteNumbers[currentBat], state)
-			currentBat++
+			setText(fmt.Sprintf("bat%v", iBat), fmt.Sprintf("Time: %.2f / Sprite: %v / %s", animationStates[iBat].CurrentTime, spriteNumbers[iBat], state))
 		}
-	}))
+	}
 
 	// Reset entities
 	if world.Resources.InputHandler.Actions[ResetAction] {
-		world.Manager.DeleteAllEntities()
-		LoadEntities("metadata/game.toml", world)
-		LoadEntities("metadata/text.toml", world)
+		world.DeleteAllEntities()
+		utils.Try(r.Get[*loader.Watcher](world.Resources)).LoadEntities("animation/metadata/game.toml", world)
+		utils.Try(r.Get[*loader.Watcher](world.Resources)).LoadEntities("animation/metadata/text.toml", world)
 	}
 }
@@ -24,8 +24,7 @@ func (game *mainGame) Layout(outsideWidth, outsideHeight int) (int, int) {
 }
 
 func (game *mainGame) Update() error {
-	game.stateMachine.Update(game.world)
-	return nil
+	return game.stateMachine.Update(game.world)
 }
 
 func (game *mainGame) Draw(screen *ebiten.Image) {
@@ -42,5 +41,7 @@ func main() {
 	ebiten.SetWindowSize(gameWidth, gameHeight)
 	ebiten.SetWindowTitle("")
 
-	utils.LogError(ebiten.RunGame(&mainGame{world, s.Init(&GameplayState{}, world)}))
+	if err := ebiten.RunGame(&mainGame{world, s.Init(&GameplayState{}, world)}); err != s.ErrQuit {
+		utils.LogError(err)
+	}
 }
@@ -1,14 +1,18 @@
 package main
 
 import (
-	"os"
+	"embed"
 
 	"github.com/x-hgg-x/goecsengine/loader"
 	"github.com/x-hgg-x/goecsengine/states"
-	"github.com/x-hgg-x/goecsengine/utils"
 	w "github.com/x-hgg-x/goecsengine/world"
 )
 
+// Game metadata is embedded in the executable
+//
+//go:embed game.toml
+var metadata embed.FS
+
 // GameplayState is the main game state
 type GameplayState struct{}
 
@@ -20,13 +24,11 @@ func (st *GameplayState) OnResume(world w.World) {}
 
 // OnStart method
 func (st *GameplayState) OnStart(world w.World) {
-	loader.LoadEntities(utils.Try(os.ReadFile("game.toml")), world, nil)
+	loader.LoadEntities(metadata, "game.toml", world)
 }
 
 // OnStop method
-func (st *GameplayState) OnStop(world w.World) {
-	world.Manager.DeleteAllEntities()
-}
+func (st *GameplayState) OnStop(world w.World) {}
 
 // Update method
 func (st *GameplayState) Update(world w.World) states.Transition {
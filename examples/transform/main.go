@@ -2,7 +2,9 @@ package main
 
 import (
 	_ "image/png"
+	"os"
 
+	c "github.com/x-hgg-x/goecsengine/components"
 	"github.com/x-hgg-x/goecsengine/loader"
 	r "github.com/x-hgg-x/goecsengine/resources"
 	s "github.com/x-hgg-x/goecsengine/states"
@@ -17,6 +19,10 @@ const (
 	gameHeight = 600
 )
 
+// Files are read from the examples directory, which contains the assets shared by all examples.
+// Metadata files reference assets with paths relative to this directory, such as "/assets/textures/gopher.png".
+var assets = os.DirFS("..")
+
 type mainGame struct {
 	world        w.World
 	stateMachine s.StateMachine
@@ -27,8 +33,7 @@ func (game *mainGame) Layout(outsideWidth, outsideHeight int) (int, int) {
 }
 
 func (game *mainGame) Update() error {
-	game.stateMachine.Update(game.world)
-	return nil
+	return game.stateMachine.Update(game.world)
 }
 
 func (game *mainGame) Draw(screen *ebiten.Image) {
@@ -36,7 +41,12 @@ func (game *mainGame) Draw(screen *ebiten.Image) {
 }
 
 func main() {
-	world := w.InitWorld(&Components{})
+	gameComponents := &Components{}
+	world := w.InitWorld(gameComponents)
+
+	// Register game components
+	c.Register[Gopher](world.Components, "Gopher", gameComponents.Gopher)
+	c.Register[Sticky](world.Components, "Sticky", gameComponents.Sticky)
 
 	// Init screen dimensions
 	world.Resources.ScreenDimensions = &r.ScreenDimensions{Width: gameWidth, Height: gameHeight}
@@ -44,21 +54,27 @@ func main() {
 	// Load controls
 	axes := []string{RotationAxis, DepthAxis}
 	actions := []string{AddEntityAction, DeleteEntityAction}
-	controls, inputHandler := loader.LoadControls("config/controls.toml", axes, actions)
+	controls, inputHandler := loader.LoadControls(assets, "transform/config/controls.toml", axes, actions)
 	world.Resources.Controls = &controls
 	world.Resources.InputHandler = &inputHandler
 
 	// Load sprite sheets
-	spriteSheets := loader.LoadSpriteSheets("metadata/spritesheets.toml")
+	spriteSheets := loader.LoadSpriteSheets(assets, "transform/metadata/spritesheets.toml")
 	world.Resources.SpriteSheets = &spriteSheets
 
+	// Load prefabs
+	prefabs := loader.LoadPrefabs(assets, "transform/metadata/prefabs.toml")
+	world.Resources.Prefabs = &prefabs
+
 	// Load fonts
-	fonts := loader.LoadFonts("metadata/fonts.toml")
+	fonts := loader.LoadFonts(assets, "transform/metadata/fonts.toml")
 	world.Resources.Fonts = &fonts
 
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
 	ebiten.SetWindowSize(gameWidth, gameHeight)
 	ebiten.SetWindowTitle("Demo")
 
-	utils.LogError(ebiten.RunGame(&mainGame{world, s.Init(&GameplayState{}, world)}))
+	if err := ebiten.RunGame(&mainGame{world, s.Init(&GameplayState{}, world)}); err != s.ErrQuit {
+		utils.LogError(err)
+	}
 }
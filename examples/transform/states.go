@@ -4,6 +4,8 @@ import (
 	"math/rand"
 	"time"
 
+	"github.com/x-hgg-x/goecsengine/loader"
+	r "github.com/x-hgg-x/goecsengine/resources"
 	"github.com/x-hgg-x/goecsengine/states"
 	w "github.com/x-hgg-x/goecsengine/world"
 
@@ -26,16 +28,15 @@ func (st *GameplayState) OnStart(world w.World) {
 	rand.Seed(time.Now().UnixNano())
 
 	// Load game and text entities
-	LoadEntities("metadata/start.toml", world)
-	LoadEntities("metadata/text.toml", world)
+	loader.LoadEntities(assets, "transform/metadata/start.toml", world)
+	loader.LoadEntities(assets, "transform/metadata/text.toml", world)
 
-	world.Resources.Game = NewGame()
+	r.Insert(world.Resources, NewGame())
 }
 
 // OnStop method
 func (st *GameplayState) OnStop(world w.World) {
-	world.Resources.Game = nil
-	world.Manager.DeleteAllEntities()
+	r.Remove[*Game](world.Resources)
 }
 
 // Update method
@@ -5,57 +5,57 @@ import (
 	"math/rand"
 
 	c "github.com/x-hgg-x/goecsengine/components"
-	m "github.com/x-hgg-x/goecsengine/math"
+	"github.com/x-hgg-x/goecsengine/loader"
+	r "github.com/x-hgg-x/goecsengine/resources"
+	"github.com/x-hgg-x/goecsengine/utils"
 	w "github.com/x-hgg-x/goecsengine/world"
 
-	"github.com/hajimehoshi/ebiten/v2"
 	ecs "github.com/x-hgg-x/goecs/v2"
 )
 
 // DemoSystem is a demo system
 func DemoSystem(world w.World) {
 	gameComponents := world.Components.Game.(*Components)
-	gameResources := world.Resources.Game.(*Game)
+	gameResources := utils.Try(r.Get[*Game](world.Resources))
 
-	gameResources.Rotation += world.Resources.InputHandler.Axes[RotationAxis] * 5 / ebiten.DefaultTPS
-	gameResources.Depth += world.Resources.InputHandler.Axes[DepthAxis] * 2 / ebiten.DefaultTPS
+	gameResources.Rotation += world.Resources.InputHandler.Axes[RotationAxis] * 5 * world.Resources.Time.Delta
+	gameResources.Depth += world.Resources.InputHandler.Axes[DepthAxis] * 2 * world.Resources.Time.Delta
 
 	world.Manager.Join(gameComponents.Gopher, world.Components.Engine.Transform).Visit(ecs.Visit(func(entity ecs.Entity) {
-		transform := world.Components.Engine.Transform.Get(entity).(*c.Transform)
+		transform := world.GetMut(entity, world.Components.Engine.Transform).(*c.Transform)
 		transform.Rotation = gameResources.Rotation
 		transform.Depth = gameResources.Depth
 	}))
 
 	// Add a gopher entity
 	if world.Resources.InputHandler.Actions[AddEntityAction] {
-		gopherEntity := LoadEntities("metadata/gopher.toml", world)
-		for iEntity := range gopherEntity {
-			transform := world.Components.Engine.Transform.Get(gopherEntity[iEntity]).(*c.Transform)
-			transform.Rotation = gameResources.Rotation
-			transform.Depth = gameResources.Depth
-			transform.Translation = m.Vector2{
-				X: float64(rand.Intn(world.Resources.ScreenDimensions.Width)),
-				Y: float64(rand.Intn(world.Resources.ScreenDimensions.Height)),
-			}
-		}
+		loader.SpawnPrefab(world, "gopher", map[string]interface{}{
+			"components": map[string]interface{}{
+				"Transform": map[string]interface{}{
+					"rotation": gameResources.Rotation,
+					"depth":    gameResources.Depth,
+					"translation": map[string]interface{}{
+						"x": float64(rand.Intn(world.Resources.ScreenDimensions.Width)),
+						"y": float64(rand.Intn(world.Resources.ScreenDimensions.Height)),
+					},
+				},
+			},
+		})
 	}
 
 	// Delete a gopher entity
 	if world.Resources.InputHandler.Actions[DeleteEntityAction] {
 		gophers := world.Manager.Join(gameComponents.Gopher, gameComponents.Sticky.Not())
 		if firstGopher := ecs.GetFirst(gophers); firstGopher != nil {
-			world.Manager.DeleteEntity(*firstGopher)
+			world.DeleteEntity(*firstGopher)
 		}
 	}
 
 	// Update text info
-	world.Manager.Join(world.Components.Engine.Text, world.Components.Engine.UITransform).Visit(ecs.Visit(func(entity ecs.Entity) {
-		text := world.Components.Engine.Text.Get(entity).(*c.Text)
-		if text.ID == "rotation" {
-			text.Text = fmt.Sprintf("Gopher rotation: %.2f", gameResources.Rotation)
-		}
-		if text.ID == "depth" {
-			text.Text = fmt.Sprintf("Gopher depth: %.2f", gameResources.Depth)
-		}
-	}))
+	if entity, ok := world.FindByName("rotation"); ok {
+		world.GetMut(entity, world.Components.Engine.Text).(*c.Text).Text = fmt.Sprintf("Gopher rotation: %.2f", gameResources.Rotation)
+	}
+	if entity, ok := world.FindByName("depth"); ok {
+		world.GetMut(entity, world.Components.Engine.Text).(*c.Text).Text = fmt.Sprintf("Gopher depth: %.2f", gameResources.Depth)
+	}
 }
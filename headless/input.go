@@ -0,0 +1,141 @@
+package headless
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+type gamepadButton struct {
+	id            ebiten.GamepadID
+	gamepadButton ebiten.GamepadButton
+}
+
+type gamepadAxis struct {
+	id   ebiten.GamepadID
+	axis int
+}
+
+type inputState struct {
+	keys           map[ebiten.Key]bool
+	mouseButtons   map[ebiten.MouseButton]bool
+	gamepadButtons map[gamepadButton]bool
+}
+
+func newInputState() inputState {
+	return inputState{
+		keys:           make(map[ebiten.Key]bool),
+		mouseButtons:   make(map[ebiten.MouseButton]bool),
+		gamepadButtons: make(map[gamepadButton]bool),
+	}
+}
+
+func (s inputState) clone() inputState {
+	cloned := newInputState()
+	for k, v := range s.keys {
+		cloned.keys[k] = v
+	}
+	for k, v := range s.mouseButtons {
+		cloned.mouseButtons[k] = v
+	}
+	for k, v := range s.gamepadButtons {
+		cloned.gamepadButtons[k] = v
+	}
+	return cloned
+}
+
+// FakeInput is an input source controlled by code.
+// A button is just pressed if it is pressed and was not pressed during the previous update.
+type FakeInput struct {
+	current     inputState
+	previous    inputState
+	gamepadAxes map[gamepadAxis]float64
+	cursorX     int
+	cursorY     int
+}
+
+// NewFakeInput creates a new fake input source with all buttons released
+func NewFakeInput() *FakeInput {
+	return &FakeInput{current: newInputState(), previous: newInputState(), gamepadAxes: make(map[gamepadAxis]float64)}
+}
+
+// PressKey presses a key
+func (f *FakeInput) PressKey(key ebiten.Key) {
+	f.current.keys[key] = true
+}
+
+// ReleaseKey releases a key
+func (f *FakeInput) ReleaseKey(key ebiten.Key) {
+	delete(f.current.keys, key)
+}
+
+// PressMouseButton presses a mouse button
+func (f *FakeInput) PressMouseButton(mouseButton ebiten.MouseButton) {
+	f.current.mouseButtons[mouseButton] = true
+}
+
+// ReleaseMouseButton releases a mouse button
+func (f *FakeInput) ReleaseMouseButton(mouseButton ebiten.MouseButton) {
+	delete(f.current.mouseButtons, mouseButton)
+}
+
+// PressGamepadButton presses a gamepad button
+func (f *FakeInput) PressGamepadButton(id ebiten.GamepadID, button ebiten.GamepadButton) {
+	f.current.gamepadButtons[gamepadButton{id, button}] = true
+}
+
+// ReleaseGamepadButton releases a gamepad button
+func (f *FakeInput) ReleaseGamepadButton(id ebiten.GamepadID, button ebiten.GamepadButton) {
+	delete(f.current.gamepadButtons, gamepadButton{id, button})
+}
+
+// SetGamepadAxisValue sets a gamepad axis value
+func (f *FakeInput) SetGamepadAxisValue(id ebiten.GamepadID, axis int, value float64) {
+	f.gamepadAxes[gamepadAxis{id, axis}] = value
+}
+
+// SetCursorPosition sets the cursor position
+func (f *FakeInput) SetCursorPosition(x, y int) {
+	f.cursorX, f.cursorY = x, y
+}
+
+// IsKeyPressed returns true if the key is pressed
+func (f *FakeInput) IsKeyPressed(key ebiten.Key) bool {
+	return f.current.keys[key]
+}
+
+// IsKeyJustPressed returns true if the key has just been pressed
+func (f *FakeInput) IsKeyJustPressed(key ebiten.Key) bool {
+	return f.current.keys[key] && !f.previous.keys[key]
+}
+
+// IsMouseButtonPressed returns true if the mouse button is pressed
+func (f *FakeInput) IsMouseButtonPressed(mouseButton ebiten.MouseButton) bool {
+	return f.current.mouseButtons[mouseButton]
+}
+
+// IsMouseButtonJustPressed returns true if the mouse button has just been pressed
+func (f *FakeInput) IsMouseButtonJustPressed(mouseButton ebiten.MouseButton) bool {
+	return f.current.mouseButtons[mouseButton] && !f.previous.mouseButtons[mouseButton]
+}
+
+// IsGamepadButtonPressed returns true if the gamepad button is pressed
+func (f *FakeInput) IsGamepadButtonPressed(id ebiten.GamepadID, button ebiten.GamepadButton) bool {
+	return f.current.gamepadButtons[gamepadButton{id, button}]
+}
+
+// IsGamepadButtonJustPressed returns true if the gamepad button has just been pressed
+func (f *FakeInput) IsGamepadButtonJustPressed(id ebiten.GamepadID, button ebiten.GamepadButton) bool {
+	return f.current.gamepadButtons[gamepadButton{id, button}] && !f.previous.gamepadButtons[gamepadButton{id, button}]
+}
+
+// GamepadAxisValue returns the gamepad axis value
+func (f *FakeInput) GamepadAxisValue(id ebiten.GamepadID, axis int) float64 {
+	return f.gamepadAxes[gamepadAxis{id, axis}]
+}
+
+// CursorPosition returns the cursor position
+func (f *FakeInput) CursorPosition() (x, y int) {
+	return f.cursorX, f.cursorY
+}
+
+// Save the input state at the end of an update
+func (f *FakeInput) endUpdate() {
+	f.previous = f.current.clone()
+}
@@ -0,0 +1,54 @@
+package headless
+
+import (
+	r "github.com/x-hgg-x/goecsengine/resources"
+	s "github.com/x-hgg-x/goecsengine/states"
+	w "github.com/x-hgg-x/goecsengine/world"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Runner runs a world and a state machine without a game window.
+// Input is read from a fake input source, and time advances by a fixed step on each update.
+// The state machine can be drawn into an offscreen image.
+type Runner struct {
+	World        w.World
+	StateMachine s.StateMachine
+	Input        *FakeInput
+}
+
+// NewRunner creates a new runner with an initial state
+func NewRunner(world w.World, state s.State, screenWidth, screenHeight int) *Runner {
+	input := NewFakeInput()
+	world.Resources.InputSource = input
+	world.Resources.ScreenDimensions = &r.ScreenDimensions{Width: screenWidth, Height: screenHeight}
+	world.Resources.Time.FixedDelta = 1 / float64(ebiten.DefaultTPS)
+
+	return &Runner{World: world, StateMachine: s.Init(state, world), Input: input}
+}
+
+// Step runs one update of the state machine
+func (runner *Runner) Step() error {
+	defer runner.Input.endUpdate()
+	return runner.StateMachine.Update(runner.World)
+}
+
+// Draw runs the draw systems of the state machine on the target image
+func (runner *Runner) Draw(target *ebiten.Image) {
+	runner.StateMachine.Draw(runner.World, target)
+}
+
+// NewScreen creates an offscreen image with the screen dimensions of the runner
+func (runner *Runner) NewScreen() *ebiten.Image {
+	return ebiten.NewImage(runner.World.Resources.ScreenDimensions.Width, runner.World.Resources.ScreenDimensions.Height)
+}
+
+// Run runs the specified number of updates, stopping at the first error
+func (runner *Runner) Run(updates int) error {
+	for iUpdate := 0; iUpdate < updates; iUpdate++ {
+		if err := runner.Step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
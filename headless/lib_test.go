@@ -0,0 +1,71 @@
+package headless
+
+import (
+	"errors"
+	"testing"
+
+	s "github.com/x-hgg-x/goecsengine/states"
+	w "github.com/x-hgg-x/goecsengine/world"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	ecs "github.com/x-hgg-x/goecs/v2"
+)
+
+type testState struct {
+	entity  ecs.Entity
+	updates int
+	stopped int
+}
+
+func (st *testState) OnStart(world w.World) {
+	st.entity = world.Resources.Commands.Spawn()
+}
+
+func (st *testState) OnStop(world w.World) {
+	st.stopped++
+}
+
+func (st *testState) OnPause(world w.World) {}
+
+func (st *testState) OnResume(world w.World) {}
+
+func (st *testState) Update(world w.World) s.Transition {
+	st.updates++
+	if world.Resources.InputSource.IsKeyJustPressed(ebiten.KeyEscape) {
+		return s.Transition{Type: s.TransQuit}
+	}
+	return s.Transition{}
+}
+
+func TestRunnerRun(t *testing.T) {
+	world := w.InitWorld(nil)
+	state := &testState{}
+	runner := NewRunner(world, state, 640, 480)
+
+	if err := runner.Run(3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state.updates != 3 {
+		t.Errorf("expected 3 updates, got %d", state.updates)
+	}
+	if world.Resources.Time.Frame != 3 || world.Resources.Time.Delta != world.Resources.Time.FixedDelta {
+		t.Errorf("expected 3 fixed time steps, got frame %d and delta time %v", world.Resources.Time.Frame, world.Resources.Time.Delta)
+	}
+	if !world.Manager.Join(world.Components.Engine.Owner).Contains(int(state.entity)) {
+		t.Errorf("expected entity spawned by the state to be owned by the state")
+	}
+
+	runner.Draw(runner.NewScreen())
+
+	// The quit transition returned on key press is applied on the next update
+	runner.Input.PressKey(ebiten.KeyEscape)
+	if err := runner.Run(2); !errors.Is(err, s.ErrQuit) {
+		t.Fatalf("expected ErrQuit, got %v", err)
+	}
+	if state.updates != 4 || state.stopped != 1 {
+		t.Errorf("expected 4 updates and 1 stop, got %d updates and %d stops", state.updates, state.stopped)
+	}
+	if world.Manager.Join().Contains(int(state.entity)) {
+		t.Errorf("expected entity owned by the stopped state to be deleted")
+	}
+}
@@ -0,0 +1,84 @@
+package loader
+
+import (
+	"fmt"
+	"io/fs"
+
+	c "github.com/x-hgg-x/goecsengine/components"
+	"github.com/x-hgg-x/goecsengine/utils"
+)
+
+// LoadAsepriteSpriteSheet loads a sprite sheet from an Aseprite JSON file, exported with the hash or array format.
+// Sprites are in the order of frames in the file, and animations are created from tags, using frame durations and tag directions.
+func LoadAsepriteSpriteSheet(fsys fs.FS, spriteSheetPath string, name string) c.SpriteSheet {
+	return utils.Try(LoadAsepriteSpriteSheetE(fsys, spriteSheetPath, name))
+}
+
+// LoadAsepriteSpriteSheetE loads a sprite sheet from an Aseprite JSON file, and returns an error if loading fails
+func LoadAsepriteSpriteSheetE(fsys fs.FS, spriteSheetPath string, name string) (c.SpriteSheet, error) {
+	data, spriteSheet, err := loadJSONSpriteSheet(fsys, spriteSheetPath, name)
+	if err != nil {
+		return c.SpriteSheet{}, err
+	}
+
+	spriteSheet.Animations = make(map[string]*c.Animation, len(data.Meta.FrameTags))
+	for _, tag := range data.Meta.FrameTags {
+		animation, err := processFrameTag(data.Frames, tag)
+		if err != nil {
+			return c.SpriteSheet{}, &Error{File: spriteSheetPath, Field: "meta.frameTags." + tag.Name, Err: err}
+		}
+		if _, ok := spriteSheet.Animations[tag.Name]; ok {
+			return c.SpriteSheet{}, &Error{File: spriteSheetPath, Field: "meta.frameTags." + tag.Name, Err: fmt.Errorf("duplicate tag name")}
+		}
+		spriteSheet.Animations[tag.Name] = animation
+	}
+	return spriteSheet, nil
+}
+
+// Create an animation from an Aseprite tag
+func processFrameTag(frames jsonFrames, tag jsonFrameTag) (*c.Animation, error) {
+	if tag.From < 0 || tag.To >= len(frames) || tag.From > tag.To {
+		return nil, fmt.Errorf("frame range %v-%v is out of range", tag.From, tag.To)
+	}
+
+	forward := []int{}
+	for iFrame := tag.From; iFrame <= tag.To; iFrame++ {
+		forward = append(forward, iFrame)
+	}
+	reverse := []int{}
+	for iFrame := tag.To; iFrame >= tag.From; iFrame-- {
+		reverse = append(reverse, iFrame)
+	}
+
+	// First and last frames are not repeated when a ping-pong is looping
+	pingPongEnd := func(frames []int) []int {
+		if len(frames) <= 2 {
+			return nil
+		}
+		return frames[1 : len(frames)-1]
+	}
+
+	var spriteNumbers []int
+	switch tag.Direction {
+	case "", "forward":
+		spriteNumbers = forward
+	case "reverse":
+		spriteNumbers = reverse
+	case "pingpong":
+		spriteNumbers = append(forward, pingPongEnd(reverse)...)
+	case "pingpong_reverse":
+		spriteNumbers = append(reverse, pingPongEnd(forward)...)
+	default:
+		return nil, fmt.Errorf("unknown tag direction: '%s'", tag.Direction)
+	}
+
+	// Convert frame durations from milliseconds to seconds
+	times := make([]float64, len(spriteNumbers)+1)
+	for iSpriteNumber, spriteNumber := range spriteNumbers {
+		if frames[spriteNumber].Duration <= 0 {
+			return nil, fmt.Errorf("duration of frame %v must be positive", spriteNumber)
+		}
+		times[iSpriteNumber+1] = times[iSpriteNumber] + float64(frames[spriteNumber].Duration)/1000
+	}
+	return &c.Animation{Time: times, SpriteNumber: spriteNumbers}, nil
+}
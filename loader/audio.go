@@ -2,9 +2,10 @@ package loader
 
 import (
 	"bytes"
+	"fmt"
 	"io"
-	"os"
-	"path/filepath"
+	"io/fs"
+	"path"
 
 	"github.com/x-hgg-x/goecsengine/utils"
 
@@ -21,20 +22,36 @@ func InitAudio(sampleRate int) *audio.Context {
 }
 
 // LoadAudio loads an audio file and returns an audio player
-func LoadAudio(audioContext *audio.Context, audioFilePath string) *audio.Player {
-	f := bytes.NewReader(utils.Try(os.ReadFile(audioFilePath)))
+func LoadAudio(fsys fs.FS, audioContext *audio.Context, audioFilePath string) *audio.Player {
+	return utils.Try(LoadAudioE(fsys, audioContext, audioFilePath))
+}
+
+// LoadAudioE loads an audio file and returns an audio player, or an error if loading fails
+func LoadAudioE(fsys fs.FS, audioContext *audio.Context, audioFilePath string) (*audio.Player, error) {
+	content, err := fs.ReadFile(fsys, audioFilePath)
+	if err != nil {
+		return nil, fileError(audioFilePath, err)
+	}
+	f := bytes.NewReader(content)
 
 	var d io.ReadSeeker
-	switch filepath.Ext(audioFilePath) {
+	switch path.Ext(audioFilePath) {
 	case ".mp3":
-		d = utils.Try(mp3.DecodeWithSampleRate(audioContext.SampleRate(), f))
+		d, err = mp3.DecodeWithSampleRate(audioContext.SampleRate(), f)
 	case ".ogg":
-		d = utils.Try(vorbis.DecodeWithSampleRate(audioContext.SampleRate(), f))
+		d, err = vorbis.DecodeWithSampleRate(audioContext.SampleRate(), f)
 	case ".wav":
-		d = utils.Try(wav.DecodeWithSampleRate(audioContext.SampleRate(), f))
+		d, err = wav.DecodeWithSampleRate(audioContext.SampleRate(), f)
 	default:
-		utils.LogFatalf("unknown audio file extension: '%s'", filepath.Ext(audioFilePath))
+		err = fmt.Errorf("unknown audio file extension: '%s'", path.Ext(audioFilePath))
+	}
+	if err != nil {
+		return nil, fileError(audioFilePath, err)
 	}
 
-	return utils.Try(audioContext.NewPlayer(d))
+	player, err := audioContext.NewPlayer(d)
+	if err != nil {
+		return nil, fileError(audioFilePath, err)
+	}
+	return player, nil
 }
@@ -1,10 +1,11 @@
 package loader
 
 import (
+	"fmt"
+	"io/fs"
+
 	"github.com/x-hgg-x/goecsengine/resources"
 	"github.com/x-hgg-x/goecsengine/utils"
-
-	"github.com/BurntSushi/toml"
 )
 
 type controlsConfig struct {
@@ -12,9 +13,16 @@ type controlsConfig struct {
 }
 
 // LoadControls loads controls from a TOML file
-func LoadControls(controlsConfigPath string, axes []string, actions []string) (resources.Controls, resources.InputHandler) {
+func LoadControls(fsys fs.FS, controlsConfigPath string, axes []string, actions []string) (resources.Controls, resources.InputHandler) {
+	return utils.Try2(LoadControlsE(fsys, controlsConfigPath, axes, actions))
+}
+
+// LoadControlsE loads controls from a TOML file, and returns an error if loading fails
+func LoadControlsE(fsys fs.FS, controlsConfigPath string, axes []string, actions []string) (resources.Controls, resources.InputHandler, error) {
 	var controlsConfig controlsConfig
-	utils.Try(toml.DecodeFile(controlsConfigPath, &controlsConfig))
+	if _, err := decodeFile(fsys, controlsConfigPath, &controlsConfig); err != nil {
+		return resources.Controls{}, resources.InputHandler{}, fileError(controlsConfigPath, err)
+	}
 
 	var inputHandler resources.InputHandler
 	inputHandler.Axes = make(map[string]float64)
@@ -23,7 +31,7 @@ func LoadControls(controlsConfigPath string, axes []string, actions []string) (r
 	// Check axes
 	for _, axis := range axes {
 		if _, ok := controlsConfig.Controls.Axes[axis]; !ok {
-			utils.LogFatalf("unable to find controls for axis '%s'", axis)
+			return resources.Controls{}, resources.InputHandler{}, &Error{File: controlsConfigPath, Field: "controls.axes." + axis, Err: fmt.Errorf("unable to find controls for axis '%s'", axis)}
 		}
 		inputHandler.Axes[axis] = 0
 	}
@@ -31,10 +39,10 @@ func LoadControls(controlsConfigPath string, axes []string, actions []string) (r
 	// Check actions
 	for _, action := range actions {
 		if _, ok := controlsConfig.Controls.Actions[action]; !ok {
-			utils.LogFatalf("unable to find controls for action '%s'", action)
+			return resources.Controls{}, resources.InputHandler{}, &Error{File: controlsConfigPath, Field: "controls.actions." + action, Err: fmt.Errorf("unable to find controls for action '%s'", action)}
 		}
 		inputHandler.Actions[action] = false
 	}
 
-	return controlsConfig.Controls, inputHandler
+	return controlsConfig.Controls, inputHandler, nil
 }
@@ -1,10 +1,14 @@
 package loader
 
 import (
+	"fmt"
 	"image/color"
+	"io/fs"
 	"reflect"
+	"sort"
 
 	c "github.com/x-hgg-x/goecsengine/components"
+	"github.com/x-hgg-x/goecsengine/resources"
 	"github.com/x-hgg-x/goecsengine/utils"
 	w "github.com/x-hgg-x/goecsengine/world"
 
@@ -23,50 +27,95 @@ type EngineComponentList struct {
 	Text             *c.Text
 	UITransform      *c.UITransform
 	MouseReactive    *c.MouseReactive
+	Name             *c.Name
+	Tags             *c.Tags
 }
 
+// GameComponentList is the list of game components, indexed by registered component name
+type GameComponentList map[string]interface{}
+
 // EntityComponentList is a list of preloaded entities with components
 type EntityComponentList struct {
 	Engine []EngineComponentList
-	Game   []interface{}
+	Game   []GameComponentList
+	// Children entities, with a Parent component referencing the corresponding entity
+	Children []EntityComponentList
 }
 
 // LoadEntities creates entities with components from a TOML file
-func LoadEntities(entityMetadataContent []byte, world w.World, gameComponentList []interface{}) []ecs.Entity {
-	entityComponentList := EntityComponentList{
-		Engine: LoadEngineComponents(entityMetadataContent, world),
-		Game:   gameComponentList,
+func LoadEntities(fsys fs.FS, entityMetadataPath string, world w.World) []ecs.Entity {
+	return utils.Try(LoadEntitiesE(fsys, entityMetadataPath, world))
+}
+
+// LoadEntitiesE creates entities with components from a TOML file, and returns an error if loading fails
+func LoadEntitiesE(fsys fs.FS, entityMetadataPath string, world w.World) ([]ecs.Entity, error) {
+	entityMetadataContent, err := fs.ReadFile(fsys, entityMetadataPath)
+	if err != nil {
+		return nil, fileError(entityMetadataPath, err)
 	}
-	return AddEntities(world, entityComponentList)
+	entityComponentList, err := LoadEntityComponentsE(entityMetadataContent, world)
+	if err != nil {
+		return nil, fileError(entityMetadataPath, err)
+	}
+	return AddEntities(world, entityComponentList), nil
 }
 
-// AddEntities adds entities with engine and game components
+// AddEntities adds entities with engine and game components.
+// Entities are owned by the current state of the state machine if any.
+// Returned entities contain the root entities, followed by their children entities.
 func AddEntities(world w.World, entityComponentList EntityComponentList) []ecs.Entity {
-	// Create new entities and add engine components
+	return addEntities(world, entityComponentList, nil)
+}
+
+func addEntities(world w.World, entityComponentList EntityComponentList, parent *ecs.Entity) []ecs.Entity {
+	if entityComponentList.Game != nil && len(entityComponentList.Game) != len(entityComponentList.Engine) {
+		utils.LogFatalf("incorrect size for game component list")
+	}
+	if entityComponentList.Children != nil && len(entityComponentList.Children) != len(entityComponentList.Engine) {
+		utils.LogFatalf("incorrect size for children component list")
+	}
+
+	// Create new entities and add engine and game components
 	entities := make([]ecs.Entity, len(entityComponentList.Engine))
 	for iEntity := range entityComponentList.Engine {
-		entities[iEntity] = world.Manager.NewEntity()
+		entities[iEntity] = newEntity(world)
+		if parent != nil {
+			entities[iEntity].AddComponent(world.Components.Engine.Parent, &c.Parent{Entity: *parent})
+		}
 		AddEntityComponents(entities[iEntity], world.Components.Engine, entityComponentList.Engine[iEntity])
+		if entityComponentList.Game != nil {
+			AddGameComponents(entities[iEntity], world.Components, entityComponentList.Game[iEntity])
+		}
+
+		// Index entity and execute component hooks
+		world.EntityCreated(entities[iEntity])
 	}
 
-	// Add game components
-	if entityComponentList.Game != nil {
-		if len(entityComponentList.Game) != len(entityComponentList.Engine) {
-			utils.LogFatalf("incorrect size for game component list")
-		}
-		for iEntity := range entities {
-			AddEntityComponents(entities[iEntity], world.Components.Game, entityComponentList.Game[iEntity])
+	// Add children entities
+	if entityComponentList.Children != nil {
+		for iEntity := range entityComponentList.Children {
+			parent := entities[iEntity]
+			entities = append(entities, addEntities(world, entityComponentList.Children[iEntity], &parent)...)
 		}
 	}
 	return entities
 }
 
+func newEntity(world w.World) ecs.Entity {
+	entity := world.Manager.NewEntity()
+	if world.Resources.CurrentOwner != nil {
+		owner := *world.Resources.CurrentOwner
+		entity.AddComponent(world.Components.Engine.Owner, &owner)
+	}
+	return entity
+}
+
 // AddEntityComponents adds loaded components to an entity
 func AddEntityComponents(entity ecs.Entity, ecsComponentList interface{}, components interface{}) ecs.Entity {
 	ecv := reflect.ValueOf(ecsComponentList).Elem()
 	cv := reflect.ValueOf(components)
 	for iField := 0; iField < cv.NumField(); iField++ {
-		if !cv.Field(iField).IsNil() {
+		if cv.Field(iField).Kind() == reflect.Ptr && !cv.Field(iField).IsNil() {
 			component := cv.Field(iField).Elem()
 			value := reflect.New(reflect.TypeOf(component.Interface()))
 			value.Elem().Set(component)
@@ -78,6 +127,22 @@ func AddEntityComponents(entity ecs.Entity, ecsComponentList interface{}, compon
 	return entity
 }
 
+// AddGameComponents adds loaded registered game components to an entity
+func AddGameComponents(entity ecs.Entity, components *c.Components, gameComponents GameComponentList) ecs.Entity {
+	for name, component := range gameComponents {
+		registered, ok := components.Registered(name)
+		if !ok {
+			utils.LogFatalf("unknown component: '%s'", name)
+		}
+		value := reflect.ValueOf(component)
+		if value.Type() != reflect.PtrTo(registered.Type) {
+			utils.LogFatalf("incorrect type for component '%s': expected '*%s', got '%s'", name, registered.Type, value.Type())
+		}
+		entity.AddComponent(registered.Component, component)
+	}
+	return entity
+}
+
 type engineComponentListData struct {
 	SpriteRender     *spriteRenderData
 	Transform        *c.Transform
@@ -85,37 +150,126 @@ type engineComponentListData struct {
 	Text             *textData
 	UITransform      *c.UITransform
 	MouseReactive    *c.MouseReactive
+	Name             *c.Name
+	Tags             *c.Tags
 }
 
 type entity struct {
-	Components engineComponentListData
+	Components map[string]toml.Primitive
+	Children   []entity
 }
 
-type entityEngineMetadata struct {
+type entityMetadata struct {
 	Entities []entity `toml:"entity"`
 }
 
-// LoadEngineComponents loads engine components from a TOML byte slice
-func LoadEngineComponents(entityMetadataContent []byte, world w.World) []EngineComponentList {
-	var entityEngineMetadata entityEngineMetadata
-	utils.Try(toml.Decode(string(entityMetadataContent), &entityEngineMetadata))
+// LoadEntityComponents loads engine and registered game components from a TOML byte slice
+func LoadEntityComponents(entityMetadataContent []byte, world w.World) EntityComponentList {
+	return utils.Try(LoadEntityComponentsE(entityMetadataContent, world))
+}
+
+// LoadEntityComponentsE loads engine and registered game components from a TOML byte slice, and returns an error if loading fails
+func LoadEntityComponentsE(entityMetadataContent []byte, world w.World) (EntityComponentList, error) {
+	var entityMetadata entityMetadata
+	metadata, err := toml.Decode(string(entityMetadataContent), &entityMetadata)
+	if err != nil {
+		return EntityComponentList{}, &Error{Err: err}
+	}
+	return processEntities(world, metadata, entityMetadata.Entities, []int{})
+}
+
+func processEntities(world w.World, metadata toml.MetaData, entities []entity, path []int) (EntityComponentList, error) {
+	entityComponentList := EntityComponentList{
+		Engine:   make([]EngineComponentList, len(entities)),
+		Game:     make([]GameComponentList, len(entities)),
+		Children: make([]EntityComponentList, len(entities)),
+	}
+	for iEntity, entity := range entities {
+		entityPath := append(append([]int{}, path...), iEntity)
+
+		engineComponents, gameComponents, err := decodeComponents(world, metadata, entity.Components)
+		if err != nil {
+			return EntityComponentList{}, entityError(entityPath, err)
+		}
+		entityComponentList.Engine[iEntity], err = processComponentsListData(world, engineComponents)
+		if err != nil {
+			return EntityComponentList{}, entityError(entityPath, err)
+		}
+		entityComponentList.Game[iEntity] = gameComponents
+		entityComponentList.Children[iEntity], err = processEntities(world, metadata, entity.Children, entityPath)
+		if err != nil {
+			return EntityComponentList{}, err
+		}
+	}
+	return entityComponentList, nil
+}
+
+// Decode engine and registered game components from component tables
+func decodeComponents(world w.World, metadata toml.MetaData, components map[string]toml.Primitive) (engineComponentListData, GameComponentList, error) {
+	var engineComponents engineComponentListData
+	engineValue := reflect.ValueOf(&engineComponents).Elem()
+	gameComponents := GameComponentList{}
 
-	engineComponentList := make([]EngineComponentList, len(entityEngineMetadata.Entities))
-	for iEntity, entity := range entityEngineMetadata.Entities {
-		engineComponentList[iEntity] = processComponentsListData(world, entity.Components)
+	// Decode components in sorted order for deterministic errors
+	names := make([]string, 0, len(components))
+	for name := range components {
+		names = append(names, name)
 	}
-	return engineComponentList
+	sort.Strings(names)
+
+	for _, name := range names {
+		var value reflect.Value
+		if field := engineValue.FieldByName(name); field.IsValid() {
+			value = reflect.New(field.Type().Elem())
+			field.Set(value)
+		} else if registered, ok := world.Components.Registered(name); ok {
+			value = reflect.New(registered.Type)
+			gameComponents[name] = value.Interface()
+		} else {
+			return engineComponentListData{}, nil, &Error{Component: name, Err: fmt.Errorf("unknown component")}
+		}
+		if err := metadata.PrimitiveDecode(components[name], value.Interface()); err != nil {
+			return engineComponentListData{}, nil, &Error{Component: name, Err: err}
+		}
+	}
+	return engineComponents, gameComponents, nil
 }
 
-func processComponentsListData(world w.World, data engineComponentListData) EngineComponentList {
+// Add the component name to an error
+func componentError(component string, err error) error {
+	loaderError := &Error{Err: err}
+	if e, ok := err.(*Error); ok {
+		componentError := *e
+		loaderError = &componentError
+	}
+	loaderError.Component = component
+	return loaderError
+}
+
+func processComponentsListData(world w.World, data engineComponentListData) (EngineComponentList, error) {
+	spriteRender, err := processSpriteRenderData(world, data.SpriteRender)
+	if err != nil {
+		return EngineComponentList{}, componentError("SpriteRender", err)
+	}
+	animationControl, err := processAnimationControlData(world.Resources.SpriteSheets, data)
+	if err != nil {
+		return EngineComponentList{}, componentError("AnimationControl", err)
+	}
+	text, err := processTextData(world, data.Text)
+	if err != nil {
+		return EngineComponentList{}, componentError("Text", err)
+	}
+
 	return EngineComponentList{
-		SpriteRender:     processSpriteRenderData(world, data.SpriteRender),
+		SpriteRender:     spriteRender,
 		Transform:        data.Transform,
-		AnimationControl: processAnimationControlData(world, data),
-		Text:             processTextData(world, data.Text),
+		AnimationControl: animationControl,
+		Text:             text,
 		UITransform:      data.UITransform,
 		MouseReactive:    data.MouseReactive,
-	}
+		Name:             data.Name,
+		Tags:             data.Tags,
+	}, nil
 }
 
 //
@@ -134,43 +288,82 @@ type spriteRenderData struct {
 	SpriteNumber    int    `toml:"sprite_number"`
 }
 
-func processSpriteRenderData(world w.World, spriteRenderData *spriteRenderData) *c.SpriteRender {
+func processSpriteRenderData(world w.World, spriteRenderData *spriteRenderData) (*c.SpriteRender, error) {
 	if spriteRenderData == nil {
-		return nil
+		return nil, nil
 	}
-	if spriteRenderData.Fill != nil && spriteRenderData.SpriteSheetName != "" {
-		utils.LogFatalf("fill and sprite_sheet_name fields are exclusive")
+	if err := checkSpriteRenderData(world.Resources.SpriteSheets, spriteRenderData); err != nil {
+		return nil, err
 	}
 
 	// Sprite is included in sprite sheet
 	if spriteRenderData.SpriteSheetName != "" {
 		// Add reference to sprite sheet
-		spriteSheet, ok := (*world.Resources.SpriteSheets)[spriteRenderData.SpriteSheetName]
-		if !ok {
-			utils.LogFatalf("unable to find sprite sheet with name '%s'", spriteRenderData.SpriteSheetName)
-		}
+		spriteSheet := (*world.Resources.SpriteSheets)[spriteRenderData.SpriteSheetName]
 		return &c.SpriteRender{
 			SpriteSheet:  &spriteSheet,
 			SpriteNumber: spriteRenderData.SpriteNumber,
-		}
+		}, nil
 	}
 
 	// Sprite is a colored rectangle
-	textureImage := ebiten.NewImage(spriteRenderData.Fill.Width, spriteRenderData.Fill.Height)
-	textureImage.Fill(color.RGBA{
+	fillColor := color.RGBA{
 		R: spriteRenderData.Fill.Color[0],
 		G: spriteRenderData.Fill.Color[1],
 		B: spriteRenderData.Fill.Color[2],
 		A: spriteRenderData.Fill.Color[3],
-	})
+	}
+	textureImage := ebiten.NewImage(spriteRenderData.Fill.Width, spriteRenderData.Fill.Height)
+	textureImage.Fill(fillColor)
 
 	return &c.SpriteRender{
 		SpriteSheet: &c.SpriteSheet{
-			Texture: c.Texture{Image: textureImage},
-			Sprites: []c.Sprite{{X: 0, Y: 0, Width: spriteRenderData.Fill.Width, Height: spriteRenderData.Fill.Height}},
+			FillColor: &fillColor,
+			Texture:   c.Texture{Image: textureImage},
+			Sprites:   []c.Sprite{{X: 0, Y: 0, Width: spriteRenderData.Fill.Width, Height: spriteRenderData.Fill.Height}},
 		},
 		SpriteNumber: 0,
+	}, nil
+}
+
+// Check sprite render data without creating images
+func checkSpriteRenderData(spriteSheets *map[string]c.SpriteSheet, spriteRenderData *spriteRenderData) error {
+	if spriteRenderData.Fill != nil && spriteRenderData.SpriteSheetName != "" {
+		return fmt.Errorf("fill and sprite_sheet_name fields are exclusive")
+	}
+	if spriteRenderData.Fill == nil && spriteRenderData.SpriteSheetName == "" {
+		return fmt.Errorf("one of fill and sprite_sheet_name fields is required")
+	}
+
+	// Sprite is included in sprite sheet
+	if spriteRenderData.SpriteSheetName != "" {
+		spriteSheet, err := findSpriteSheet(spriteSheets, spriteRenderData.SpriteSheetName)
+		if err != nil {
+			return &Error{Field: "sprite_sheet_name", Err: err}
+		}
+		if spriteRenderData.SpriteNumber < 0 || spriteRenderData.SpriteNumber >= len(spriteSheet.Sprites) {
+			return &Error{Field: "sprite_number", Err: fmt.Errorf("sprite number %v is out of range for sprite sheet '%s'", spriteRenderData.SpriteNumber, spriteRenderData.SpriteSheetName)}
+		}
+		return nil
+	}
+
+	// Sprite is a colored rectangle
+	if spriteRenderData.Fill.Width <= 0 || spriteRenderData.Fill.Height <= 0 {
+		return &Error{Field: "fill", Err: fmt.Errorf("fill dimensions must be positive")}
 	}
+	return nil
+}
+
+// Find a sprite sheet from its name
+func findSpriteSheet(spriteSheets *map[string]c.SpriteSheet, name string) (c.SpriteSheet, error) {
+	if spriteSheets == nil {
+		return c.SpriteSheet{}, fmt.Errorf("sprite sheets are not loaded")
+	}
+	spriteSheet, ok := (*spriteSheets)[name]
+	if !ok {
+		return c.SpriteSheet{}, fmt.Errorf("unable to find sprite sheet with name '%s'", name)
+	}
+	return spriteSheet, nil
 }
 
 //
@@ -205,69 +398,86 @@ type animationCommandData struct {
 	Time float64
 }
 
+var controlStateMap = map[string]c.ControlStateType{
+	"":           c.ControlStateNotStarted,
+	"NotStarted": c.ControlStateNotStarted,
+	"Running":    c.ControlStateRunning,
+	"Paused":     c.ControlStatePaused,
+	"Done":       c.ControlStateDone,
+}
+
+type controlStateData struct {
+	Type        string
+	CurrentTime float64 `toml:"current_time"`
+}
+
 type animationControlData struct {
 	SpriteSheetName string `toml:"sprite_sheet_name"`
 	AnimationName   string `toml:"animation_name"`
 	End             endControlData
 	Command         animationCommandData
 	RateMultiplier1 float64 `toml:"rate_multiplier_minus_1"`
+	State           controlStateData
 }
 
-func processAnimationControlData(world w.World, data engineComponentListData) *c.AnimationControl {
+func processAnimationControlData(spriteSheets *map[string]c.SpriteSheet, data engineComponentListData) (*c.AnimationControl, error) {
 	animationControlData := data.AnimationControl
 	spriteRenderData := data.SpriteRender
-	if animationControlData == nil || spriteRenderData == nil {
-		return nil
+	if animationControlData == nil {
+		return nil, nil
+	}
+	if spriteRenderData == nil {
+		return nil, fmt.Errorf("AnimationControl component requires a SpriteRender component")
 	}
 
 	// Find spritesheet
 	if animationControlData.SpriteSheetName != spriteRenderData.SpriteSheetName {
-		utils.LogFatalf("AnimationControl and SpriteRender components don't have the same sprite sheet ('%s' vs '%s')", animationControlData.SpriteSheetName, spriteRenderData.SpriteSheetName)
+		return nil, &Error{Field: "sprite_sheet_name", Err: fmt.Errorf("AnimationControl and SpriteRender components don't have the same sprite sheet ('%s' vs '%s')", animationControlData.SpriteSheetName, spriteRenderData.SpriteSheetName)}
 	}
-	spriteSheet, ok := (*world.Resources.SpriteSheets)[animationControlData.SpriteSheetName]
-	if !ok {
-		utils.LogFatalf("unable to find sprite sheet with name '%s'", animationControlData.SpriteSheetName)
+	spriteSheet, err := findSpriteSheet(spriteSheets, animationControlData.SpriteSheetName)
+	if err != nil {
+		return nil, &Error{Field: "sprite_sheet_name", Err: err}
 	}
 
 	// Find animation
 	animation, ok := spriteSheet.Animations[animationControlData.AnimationName]
 	if !ok {
-		utils.LogFatalf("unable to find animation with name '%s'", animationControlData.AnimationName)
+		return nil, &Error{Field: "animation_name", Err: fmt.Errorf("unable to find animation with name '%s'", animationControlData.AnimationName)}
 	}
 
 	// Check end control
 	endControl, ok := endControlMap[animationControlData.End.Type]
 	if !ok {
-		utils.LogFatalf("unknown end control option: '%s'", animationControlData.End.Type)
+		return nil, &Error{Field: "end.type", Err: fmt.Errorf("unknown end control option: '%s'", animationControlData.End.Type)}
 	}
 
 	// Check animation command
 	animationCommand, ok := animationCommandMap[animationControlData.Command.Type]
 	if !ok {
-		utils.LogFatalf("unknown animation command option: '%s'", animationControlData.Command.Type)
+		return nil, &Error{Field: "command.type", Err: fmt.Errorf("unknown animation command option: '%s'", animationControlData.Command.Type)}
 	}
 
-	return &c.AnimationControl{
+	// Check control state
+	controlState, ok := controlStateMap[animationControlData.State.Type]
+	if !ok {
+		return nil, &Error{Field: "state.type", Err: fmt.Errorf("unknown control state option: '%s'", animationControlData.State.Type)}
+	}
+
+	animationControl := &c.AnimationControl{
 		Animation:      animation,
 		End:            c.EndControl{Type: endControl},
 		Command:        c.AnimationCommand{Type: animationCommand, Time: animationControlData.Command.Time},
 		RateMultiplier: animationControlData.RateMultiplier1 + 1,
 	}
+	animationControl.SetStateType(controlState)
+	animationControl.SetCurrentTime(animationControlData.State.CurrentTime)
+	return animationControl, nil
 }
 
 //
 // Text
 //
 
-type fontFaceOptions struct {
-	Size              float64
-	DPI               float64
-	Hinting           string
-	GlyphCacheEntries int `toml:"glyph_cache_entries"`
-	SubPixelsX        int `toml:"sub_pixels_x"`
-	SubPixelsY        int `toml:"sub_pixels_y"`
-}
-
 var hintingMap = map[string]font.Hinting{
 	"":         font.HintingFull,
 	"None":     font.HintingNone,
@@ -277,7 +487,7 @@ var hintingMap = map[string]font.Hinting{
 
 type fontFaceData struct {
 	Font    string
-	Options fontFaceOptions
+	Options c.FontFaceOptions
 }
 
 type textData struct {
@@ -287,36 +497,58 @@ type textData struct {
 	Color    [4]uint8
 }
 
-func processTextData(world w.World, textData *textData) *c.Text {
+func processTextData(world w.World, textData *textData) (*c.Text, error) {
 	if textData == nil {
-		return nil
+		return nil, nil
 	}
 
 	// Search font from its name
-	textFont, ok := (*world.Resources.Fonts)[textData.FontFace.Font]
+	textFont, err := findFont(world.Resources.Fonts, textData.FontFace.Font)
+	if err != nil {
+		return nil, &Error{Field: "font_face.font", Err: err}
+	}
+
+	fontFace, err := newFontFace(textFont, textData.FontFace.Options)
+	if err != nil {
+		return nil, &Error{Field: "font_face.options.hinting", Err: err}
+	}
+
+	return &c.Text{
+		ID:              textData.ID,
+		Text:            textData.Text,
+		FontFace:        fontFace,
+		Color:           color.RGBA{R: textData.Color[0], G: textData.Color[1], B: textData.Color[2], A: textData.Color[3]},
+		Font:            textData.FontFace.Font,
+		FontFaceOptions: textData.FontFace.Options,
+	}, nil
+}
+
+// Find a font from its name
+func findFont(fonts *map[string]resources.Font, name string) (resources.Font, error) {
+	if fonts == nil {
+		return resources.Font{}, fmt.Errorf("fonts are not loaded")
+	}
+	textFont, ok := (*fonts)[name]
 	if !ok {
-		utils.LogFatalf("unable to find font with name '%s'", textData.FontFace.Font)
+		return resources.Font{}, fmt.Errorf("unable to find font with name '%s'", name)
 	}
+	return textFont, nil
+}
 
+// Create a font face from a font and options
+func newFontFace(textFont resources.Font, options c.FontFaceOptions) (font.Face, error) {
 	// Check hinting
-	hinting, ok := hintingMap[textData.FontFace.Options.Hinting]
+	hinting, ok := hintingMap[options.Hinting]
 	if !ok {
-		utils.LogFatalf("unknown hinting option: '%s'", textData.FontFace.Options.Hinting)
+		return nil, fmt.Errorf("unknown hinting option: '%s'", options.Hinting)
 	}
 
-	options := &truetype.Options{
-		Size:              textData.FontFace.Options.Size,
-		DPI:               textData.FontFace.Options.DPI,
+	return truetype.NewFace(textFont.Font, &truetype.Options{
+		Size:              options.Size,
+		DPI:               options.DPI,
 		Hinting:           hinting,
-		GlyphCacheEntries: textData.FontFace.Options.GlyphCacheEntries,
-		SubPixelsX:        textData.FontFace.Options.SubPixelsX,
-		SubPixelsY:        textData.FontFace.Options.SubPixelsY,
-	}
-
-	return &c.Text{
-		ID:       textData.ID,
-		Text:     textData.Text,
-		FontFace: truetype.NewFace(textFont.Font, options),
-		Color:    color.RGBA{R: textData.Color[0], G: textData.Color[1], B: textData.Color[2], A: textData.Color[3]},
-	}
+		GlyphCacheEntries: options.GlyphCacheEntries,
+		SubPixelsX:        options.SubPixelsX,
+		SubPixelsY:        options.SubPixelsY,
+	}), nil
 }
@@ -0,0 +1,73 @@
+package loader
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Error is an error which occurred when loading data, with its context
+type Error struct {
+	// Path of the loaded file
+	File string
+	// Index path of the entity, with the root entity index followed by the children indices
+	Entity []int
+	// Component name
+	Component string
+	// Field name
+	Field string
+	// Underlying error
+	Err error
+}
+
+// Error returns the error message with its context
+func (e *Error) Error() string {
+	context := []string{}
+	if e.File != "" {
+		context = append(context, fmt.Sprintf("file '%s'", e.File))
+	}
+	if e.Entity != nil {
+		indices := make([]string, len(e.Entity))
+		for iIndex, index := range e.Entity {
+			indices[iIndex] = fmt.Sprint(index)
+		}
+		context = append(context, fmt.Sprintf("entity %s", strings.Join(indices, "/")))
+	}
+	if e.Component != "" {
+		context = append(context, fmt.Sprintf("component '%s'", e.Component))
+	}
+	if e.Field != "" {
+		context = append(context, fmt.Sprintf("field '%s'", e.Field))
+	}
+	return strings.Join(append(context, e.Err.Error()), ": ")
+}
+
+// Unwrap returns the underlying error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Add the file path to an error
+func fileError(file string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if loaderError, ok := err.(*Error); ok {
+		fileError := *loaderError
+		fileError.File = file
+		return &fileError
+	}
+	return &Error{File: file, Err: err}
+}
+
+// Add the entity index path to an error
+func entityError(entity []int, err error) error {
+	if loaderError, ok := err.(*Error); ok {
+		if loaderError.Entity != nil {
+			return err
+		}
+		entityError := *loaderError
+		entityError.Entity = entity
+		return &entityError
+	}
+	return &Error{Entity: entity, Err: err}
+}
@@ -1,19 +1,53 @@
 package loader
 
 import (
+	"fmt"
+	"io/fs"
+	"sort"
+
 	"github.com/x-hgg-x/goecsengine/resources"
 	"github.com/x-hgg-x/goecsengine/utils"
 
-	"github.com/BurntSushi/toml"
+	"github.com/golang/freetype/truetype"
 )
 
 type fontMetadata struct {
 	Fonts map[string]resources.Font `toml:"font"`
 }
 
-// LoadFonts loads fonts from a TOML file
-func LoadFonts(fontPath string) map[string]resources.Font {
+// LoadFonts loads fonts from a TOML file.
+// Font file paths are relative to the TOML file.
+func LoadFonts(fsys fs.FS, fontPath string) map[string]resources.Font {
+	return utils.Try(LoadFontsE(fsys, fontPath))
+}
+
+// LoadFontsE loads fonts from a TOML file, and returns an error if loading fails
+func LoadFontsE(fsys fs.FS, fontPath string) (map[string]resources.Font, error) {
 	var fontMetadata fontMetadata
-	utils.Try(toml.DecodeFile(fontPath, &fontMetadata))
-	return fontMetadata.Fonts
+	if _, err := decodeFile(fsys, fontPath, &fontMetadata); err != nil {
+		return nil, fileError(fontPath, err)
+	}
+
+	// Load fonts in sorted order for deterministic errors
+	names := make([]string, 0, len(fontMetadata.Fonts))
+	for name := range fontMetadata.Fonts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		font := fontMetadata.Fonts[name]
+		fontFilePath := resolvePath(fontPath, font.Path)
+
+		fontFile, err := fs.ReadFile(fsys, fontFilePath)
+		if err != nil {
+			return nil, &Error{File: fontPath, Field: "font." + name + ".font", Err: err}
+		}
+		if font.Font, err = truetype.Parse(fontFile); err != nil {
+			return nil, &Error{File: fontPath, Field: "font." + name + ".font", Err: fmt.Errorf("unable to parse font '%s': %w", fontFilePath, err)}
+		}
+		font.Path = fontFilePath
+		fontMetadata.Fonts[name] = font
+	}
+	return fontMetadata.Fonts, nil
 }
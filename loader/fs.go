@@ -0,0 +1,38 @@
+package loader
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// OSFS is a file system reading files from the operating system, relative to the working directory.
+// Unlike os.DirFS, paths can contain ".." elements.
+type OSFS struct{}
+
+// Open opens the named file
+func (OSFS) Open(name string) (fs.File, error) {
+	return os.Open(filepath.FromSlash(name))
+}
+
+// Decode a TOML file from a file system
+func decodeFile(fsys fs.FS, filePath string, data interface{}) (toml.MetaData, error) {
+	content, err := fs.ReadFile(fsys, filePath)
+	if err != nil {
+		return toml.MetaData{}, err
+	}
+	return toml.Decode(string(content), data)
+}
+
+// Resolve a path referenced in a file, relative to the directory of the file.
+// Paths starting with a slash are relative to the root of the file system.
+func resolvePath(file string, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return path.Clean(strings.TrimPrefix(target, "/"))
+	}
+	return path.Join(path.Dir(file), target)
+}
@@ -0,0 +1,77 @@
+package loader
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Rectangle in a JSON sprite sheet
+type jsonRect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// Frame in a JSON sprite sheet
+type jsonFrame struct {
+	Filename string   `json:"filename"`
+	Frame    jsonRect `json:"frame"`
+	Rotated  bool     `json:"rotated"`
+	// Frame duration in milliseconds, only exported by Aseprite
+	Duration int `json:"duration"`
+}
+
+// List of frames in a JSON sprite sheet.
+// Frames can be exported as an array, or as an object indexed by file name, in which case the order of frames is preserved.
+type jsonFrames []jsonFrame
+
+// UnmarshalJSON fills frames from JSON data
+func (f *jsonFrames) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		return json.Unmarshal(data, (*[]jsonFrame)(f))
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil {
+		return err
+	} else if token != json.Delim('{') {
+		return fmt.Errorf("frames must be an array or an object")
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		var frame jsonFrame
+		if err := decoder.Decode(&frame); err != nil {
+			return err
+		}
+		frame.Filename = token.(string)
+		*f = append(*f, frame)
+	}
+	_, err := decoder.Token()
+	return err
+}
+
+// Metadata of a JSON sprite sheet
+type jsonMeta struct {
+	Image string `json:"image"`
+	// Animation tags, only exported by Aseprite
+	FrameTags []jsonFrameTag `json:"frameTags"`
+}
+
+// Animation tag of an Aseprite sprite sheet
+type jsonFrameTag struct {
+	Name      string `json:"name"`
+	From      int    `json:"from"`
+	To        int    `json:"to"`
+	Direction string `json:"direction"`
+}
+
+// JSON sprite sheet exported by Aseprite or TexturePacker
+type jsonSpriteSheet struct {
+	Frames jsonFrames `json:"frames"`
+	Meta   jsonMeta   `json:"meta"`
+}
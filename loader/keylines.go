@@ -0,0 +1,266 @@
+package loader
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// keyLines contains the lines of keys in a TOML file.
+// The decoded metadata lists all key occurrences in document order, but without their positions and array indices,
+// so each key is searched in the file content from the position of the previous key.
+type keyLines struct {
+	// Key occurrences, in document order
+	occurrences []keyOccurrence
+	// Lines of keys, indexed by key path with array indices
+	lines map[string]int
+}
+
+type keyOccurrence struct {
+	key  toml.Key
+	path []string
+	line int
+}
+
+// Position in the file content, with a line index and a column
+type keyPosition struct {
+	line   int
+	column int
+}
+
+// Elements of an inline array of tables, where a new element starts when one of its keys is repeated
+type arrayElements struct {
+	index int
+	seen  map[string]bool
+}
+
+func newKeyLines(content string, metadata toml.MetaData) *keyLines {
+	keyLines := &keyLines{lines: make(map[string]int)}
+
+	contentLines := strings.Split(content, "\n")
+	for iLine := range contentLines {
+		contentLines[iLine] = stripComment(contentLines[iLine])
+	}
+
+	// Lengths of arrays of tables and elements of inline arrays, indexed by key path with array indices
+	arrayLengths := make(map[string]int)
+	elements := make(map[string]*arrayElements)
+
+	position := keyPosition{}
+	var previous toml.Key
+	for _, key := range metadata.Keys() {
+		keyType := metadata.Type(key...)
+		path := resolveKeyPath(metadata, key, arrayLengths, elements)
+
+		// Tables and arrays defined inline are listed after their content
+		closing := (keyType == "Hash" || keyType == "Array") && len(previous) > len(key) && isKeyPrefix(key, previous)
+
+		var line int
+		var ok bool
+		if closing {
+			line, ok = findKeyBackward(contentLines, key, position)
+		} else {
+			var found keyPosition
+			if found, ok = findKeyForward(contentLines, key, keyType, position); ok {
+				position = found
+				line = found.line
+			}
+		}
+		previous = key
+
+		if !ok {
+			continue
+		}
+		keyLines.occurrences = append(keyLines.occurrences, keyOccurrence{key: key, path: path, line: line + 1})
+		keyLines.add(path, line+1)
+	}
+	return keyLines
+}
+
+// Add array indices to a key path, counting tables of arrays of tables and elements of inline arrays
+func resolveKeyPath(metadata toml.MetaData, key toml.Key, arrayLengths map[string]int, elements map[string]*arrayElements) []string {
+	path := []string{}
+	for iSegment, segment := range key {
+		path = append(path, segment)
+		pathKey := strings.Join(path, ".")
+
+		if iSegment == len(key)-1 {
+			// New table in an array of tables
+			if metadata.Type(key...) == "ArrayHash" {
+				arrayLengths[pathKey]++
+				path = append(path, strconv.Itoa(arrayLengths[pathKey]-1))
+			}
+			break
+		}
+
+		if length, ok := arrayLengths[pathKey]; ok {
+			path = append(path, strconv.Itoa(length-1))
+		} else if metadata.Type(key[:iSegment+1]...) == "Array" {
+			array, ok := elements[pathKey]
+			if !ok {
+				array = &arrayElements{seen: make(map[string]bool)}
+				elements[pathKey] = array
+			}
+			next := key[iSegment+1]
+			if array.seen[next] {
+				array.index++
+				array.seen = make(map[string]bool)
+			}
+			if iSegment+2 == len(key) {
+				array.seen[next] = true
+			}
+			path = append(path, strconv.Itoa(array.index))
+		}
+	}
+	return path
+}
+
+func (keyLines *keyLines) add(path []string, line int) {
+	keyLines.lines[strings.Join(path, ".")] = line
+
+	// Elements of inline arrays start at their first key
+	for length := len(path) - 1; length > 0; length-- {
+		if _, err := strconv.Atoi(path[length-1]); err != nil {
+			break
+		}
+		pathKey := strings.Join(path[:length], ".")
+		if _, ok := keyLines.lines[pathKey]; ok {
+			break
+		}
+		keyLines.lines[pathKey] = line
+	}
+}
+
+// Find the line of a key path with array indices, or of its nearest parent key.
+// The line is 0 if the key path is not found.
+func (keyLines *keyLines) line(path []string) int {
+	if keyLines == nil {
+		return 0
+	}
+	for length := len(path); length > 0; length-- {
+		if line, ok := keyLines.lines[strings.Join(path[:length], ".")]; ok {
+			return line
+		}
+	}
+	return 0
+}
+
+// Check if a key is a strict prefix of another key
+func isKeyPrefix(prefix toml.Key, key toml.Key) bool {
+	if len(prefix) >= len(key) {
+		return false
+	}
+	for iSegment := range prefix {
+		if prefix[iSegment] != key[iSegment] {
+			return false
+		}
+	}
+	return true
+}
+
+// Find the first definition of a key after a position, in a table header or in an assignment
+func findKeyForward(contentLines []string, key toml.Key, keyType string, position keyPosition) (keyPosition, bool) {
+	assignment := assignmentRegexp(key[len(key)-1])
+	for iLine := position.line; iLine < len(contentLines); iLine++ {
+		column := 0
+		if iLine == position.line {
+			column = position.column
+		}
+		line := contentLines[iLine]
+
+		if (keyType == "Hash" || keyType == "ArrayHash") && column == 0 && isTableHeader(line, key) {
+			return keyPosition{line: iLine, column: len(line)}, true
+		}
+		for _, match := range assignment.FindAllStringIndex(line, -1) {
+			if match[0] >= column {
+				return keyPosition{line: iLine, column: match[1]}, true
+			}
+		}
+	}
+	return keyPosition{}, false
+}
+
+// Find the last assignment of a key before a position
+func findKeyBackward(contentLines []string, key toml.Key, position keyPosition) (int, bool) {
+	assignment := assignmentRegexp(key[len(key)-1])
+	for iLine := position.line; iLine >= 0 && iLine < len(contentLines); iLine-- {
+		line := contentLines[iLine]
+		if iLine == position.line {
+			line = line[:position.column]
+		}
+		if assignment.MatchString(line) {
+			return iLine, true
+		}
+	}
+	return 0, false
+}
+
+// Match the assignment of a bare or quoted key segment, including the last segment of a dotted key
+func assignmentRegexp(segment string) *regexp.Regexp {
+	quoted := regexp.QuoteMeta(segment)
+	return regexp.MustCompile(`(?:^|[\s{,.])(?:` + quoted + `|"` + quoted + `"|'` + quoted + `')\s*=`)
+}
+
+// Check if a line is the table header or the array of tables header of a key
+func isTableHeader(line string, key toml.Key) bool {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "[") || !strings.HasSuffix(line, "]") {
+		return false
+	}
+	header := strings.TrimSuffix(strings.TrimPrefix(strings.TrimSuffix(strings.TrimPrefix(line, "["), "]"), "["), "]")
+	segments := splitKey(header)
+	if len(segments) != len(key) {
+		return false
+	}
+	for iSegment := range segments {
+		if segments[iSegment] != key[iSegment] {
+			return false
+		}
+	}
+	return true
+}
+
+// Split a dotted key into segments, removing quotes
+func splitKey(key string) []string {
+	segments := []string{}
+	var segment strings.Builder
+	var quote rune
+	for _, char := range key {
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			} else {
+				segment.WriteRune(char)
+			}
+		case char == '"' || char == '\'':
+			quote = char
+		case char == '.':
+			segments = append(segments, strings.TrimSpace(segment.String()))
+			segment.Reset()
+		default:
+			segment.WriteRune(char)
+		}
+	}
+	return append(segments, strings.TrimSpace(segment.String()))
+}
+
+// Remove a comment from a line
+func stripComment(line string) string {
+	var quote rune
+	for iChar, char := range line {
+		switch {
+		case quote != 0:
+			if char == quote && (quote == '\'' || iChar == 0 || line[iChar-1] != '\\') {
+				quote = 0
+			}
+		case char == '"' || char == '\'':
+			quote = char
+		case char == '#':
+			return line[:iChar]
+		}
+	}
+	return line
+}
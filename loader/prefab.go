@@ -0,0 +1,140 @@
+package loader
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/x-hgg-x/goecsengine/resources"
+	"github.com/x-hgg-x/goecsengine/utils"
+	w "github.com/x-hgg-x/goecsengine/world"
+
+	"github.com/BurntSushi/toml"
+	ecs "github.com/x-hgg-x/goecs/v2"
+)
+
+type prefabMetadata struct {
+	Prefabs map[string]map[string]interface{} `toml:"prefab"`
+}
+
+// LoadPrefabs loads prefabs from a TOML file.
+// A prefab can extend another prefab with the extends field, and its fields are merged with the fields of the extended prefab.
+func LoadPrefabs(fsys fs.FS, prefabMetadataPath string) map[string]resources.Prefab {
+	return utils.Try(LoadPrefabsE(fsys, prefabMetadataPath))
+}
+
+// LoadPrefabsE loads prefabs from a TOML file, and returns an error if loading fails
+func LoadPrefabsE(fsys fs.FS, prefabMetadataPath string) (map[string]resources.Prefab, error) {
+	var prefabMetadata prefabMetadata
+	if _, err := decodeFile(fsys, prefabMetadataPath, &prefabMetadata); err != nil {
+		return nil, fileError(prefabMetadataPath, err)
+	}
+
+	// Resolve prefabs in sorted order for deterministic errors
+	names := make([]string, 0, len(prefabMetadata.Prefabs))
+	for name := range prefabMetadata.Prefabs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	prefabs := make(map[string]resources.Prefab, len(prefabMetadata.Prefabs))
+	for _, name := range names {
+		if _, err := resolvePrefab(name, prefabMetadata.Prefabs, prefabs, []string{}); err != nil {
+			return nil, fileError(prefabMetadataPath, err)
+		}
+	}
+	return prefabs, nil
+}
+
+// Resolve prefab inheritance
+func resolvePrefab(name string, data map[string]map[string]interface{}, prefabs map[string]resources.Prefab, visited []string) (resources.Prefab, error) {
+	if prefab, ok := prefabs[name]; ok {
+		return prefab, nil
+	}
+	for _, visitedName := range visited {
+		if visitedName == name {
+			return nil, fmt.Errorf("cycle found in prefab inheritance: %s", strings.Join(append(visited, name), " -> "))
+		}
+	}
+
+	prefabData, ok := data[name]
+	if !ok {
+		return nil, fmt.Errorf("unable to find prefab with name '%s'", name)
+	}
+
+	prefab := resources.Prefab{}
+	if extends, ok := prefabData["extends"]; ok {
+		parentName, ok := extends.(string)
+		if !ok {
+			return nil, &Error{Field: "prefab." + name + ".extends", Err: fmt.Errorf("extends field must be a string")}
+		}
+		var err error
+		if prefab, err = resolvePrefab(parentName, data, prefabs, append(visited, name)); err != nil {
+			return nil, err
+		}
+	}
+
+	fields := make(map[string]interface{}, len(prefabData))
+	for key, value := range prefabData {
+		if key != "extends" {
+			fields[key] = value
+		}
+	}
+
+	prefab = mergeData(prefab, fields)
+	prefabs[name] = prefab
+	return prefab, nil
+}
+
+// Merge TOML data recursively, without modifying the input data.
+// Tables are merged, and other values of the base data are replaced.
+func mergeData(base map[string]interface{}, overrides map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(overrides))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range overrides {
+		baseTable, baseIsTable := merged[key].(map[string]interface{})
+		overrideTable, overrideIsTable := value.(map[string]interface{})
+		if baseIsTable && overrideIsTable {
+			merged[key] = mergeData(baseTable, overrideTable)
+		} else {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// SpawnPrefab creates entities from a prefab, with overridden fields.
+// Overrides have the same structure as the prefab, for example:
+//
+//	map[string]interface{}{"components": map[string]interface{}{"Transform": map[string]interface{}{"depth": 1.0}}}
+func SpawnPrefab(world w.World, name string, overrides map[string]interface{}) []ecs.Entity {
+	return utils.Try(SpawnPrefabE(world, name, overrides))
+}
+
+// SpawnPrefabE creates entities from a prefab with overridden fields, and returns an error if loading fails
+func SpawnPrefabE(world w.World, name string, overrides map[string]interface{}) ([]ecs.Entity, error) {
+	if world.Resources.Prefabs == nil {
+		return nil, fmt.Errorf("prefabs are not loaded")
+	}
+	prefab, ok := (*world.Resources.Prefabs)[name]
+	if !ok {
+		return nil, fmt.Errorf("unable to find prefab with name '%s'", name)
+	}
+
+	// Load after serialization, so that prefabs are decoded like entity files
+	var encoded strings.Builder
+	if err := toml.NewEncoder(&encoded).Encode(map[string]interface{}{
+		"entity": []map[string]interface{}{mergeData(prefab, overrides)},
+	}); err != nil {
+		return nil, fmt.Errorf("prefab '%s': %w", name, err)
+	}
+
+	entityComponentList, err := LoadEntityComponentsE([]byte(encoded.String()), world)
+	if err != nil {
+		return nil, fmt.Errorf("prefab '%s': %w", name, err)
+	}
+	return AddEntities(world, entityComponentList), nil
+}
@@ -0,0 +1,278 @@
+package loader
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"reflect"
+	"sort"
+	"strings"
+
+	c "github.com/x-hgg-x/goecsengine/components"
+	"github.com/x-hgg-x/goecsengine/utils"
+	w "github.com/x-hgg-x/goecsengine/world"
+
+	"github.com/BurntSushi/toml"
+	ecs "github.com/x-hgg-x/goecs/v2"
+)
+
+// SaveEntities serializes all entities with engine and registered game components to a TOML byte slice.
+// The output uses the same format as LoadEntities, with children entities nested in their parent.
+// Sprite sheets, animations and fonts are referenced by their resource names.
+func SaveEntities(world w.World) []byte {
+	return utils.Try(SaveEntitiesE(world))
+}
+
+// SaveEntitiesE serializes all entities with engine and registered game components to a TOML byte slice, and returns an error if saving fails
+func SaveEntitiesE(world w.World) ([]byte, error) {
+	// Find children of each entity
+	aliveEntities := world.Manager.Join()
+	children := make(map[ecs.Entity][]ecs.Entity)
+	roots := []ecs.Entity{}
+	aliveEntities.Visit(ecs.Visit(func(entity ecs.Entity) {
+		if entity.HasComponent(world.Components.Engine.Parent) {
+			if parent := world.Components.Engine.Parent.Get(entity).(*c.Parent).Entity; aliveEntities.Contains(int(parent)) {
+				children[parent] = append(children[parent], entity)
+				return
+			}
+		}
+		roots = append(roots, entity)
+	}))
+
+	savedEntities, err := saveEntities(world, roots, children, []int{})
+	if err != nil {
+		return nil, err
+	}
+
+	var encoded bytes.Buffer
+	encoder := toml.NewEncoder(&encoded)
+	encoder.Indent = ""
+	if err := encoder.Encode(savedEntityMetadata{Entities: savedEntities}); err != nil {
+		return nil, &Error{Err: err}
+	}
+	return encoded.Bytes(), nil
+}
+
+type savedEntity struct {
+	Components map[string]interface{} `toml:"components"`
+	Children   []savedEntity          `toml:"children,omitempty"`
+}
+
+type savedEntityMetadata struct {
+	Entities []savedEntity `toml:"entity"`
+}
+
+func saveEntities(world w.World, entities []ecs.Entity, children map[ecs.Entity][]ecs.Entity, path []int) ([]savedEntity, error) {
+	savedEntities := make([]savedEntity, len(entities))
+	for iEntity, entity := range entities {
+		entityPath := append(append([]int{}, path...), iEntity)
+
+		components, err := saveComponents(world, entity)
+		if err != nil {
+			return nil, entityError(entityPath, err)
+		}
+		savedChildren, err := saveEntities(world, children[entity], children, entityPath)
+		if err != nil {
+			return nil, err
+		}
+		savedEntities[iEntity] = savedEntity{Components: components, Children: savedChildren}
+	}
+	return savedEntities, nil
+}
+
+func saveComponents(world w.World, entity ecs.Entity) (map[string]interface{}, error) {
+	engine := world.Components.Engine
+	components := make(map[string]interface{})
+
+	if entity.HasComponent(engine.SpriteRender) {
+		spriteRender, err := saveSpriteRender(engine.SpriteRender.Get(entity).(*c.SpriteRender))
+		if err != nil {
+			return nil, componentError("SpriteRender", err)
+		}
+		components["SpriteRender"] = spriteRender
+	}
+	if entity.HasComponent(engine.AnimationControl) {
+		if !entity.HasComponent(engine.SpriteRender) {
+			return nil, componentError("AnimationControl", fmt.Errorf("unable to save AnimationControl component without SpriteRender component"))
+		}
+		spriteRender := engine.SpriteRender.Get(entity).(*c.SpriteRender)
+		animationControl, err := saveAnimationControl(spriteRender, engine.AnimationControl.Get(entity).(*c.AnimationControl))
+		if err != nil {
+			return nil, componentError("AnimationControl", err)
+		}
+		components["AnimationControl"] = animationControl
+	}
+	if entity.HasComponent(engine.Text) {
+		text, err := saveText(engine.Text.Get(entity).(*c.Text))
+		if err != nil {
+			return nil, componentError("Text", err)
+		}
+		components["Text"] = text
+	}
+
+	// Components without dynamic data
+	for _, name := range []string{"Transform", "UITransform", "MouseReactive", "Name", "Tags"} {
+		component := reflect.ValueOf(engine).Elem().FieldByName(name).Interface().(ecs.DataComponent)
+		if entity.HasComponent(component) {
+			components[name] = saveValue(reflect.ValueOf(component.Get(entity)))
+		}
+	}
+
+	// Registered game components
+	for _, name := range world.Components.RegisteredNames() {
+		registered, _ := world.Components.Registered(name)
+		if entity.HasComponent(registered.Component) {
+			if data := registered.Component.Get(entity); data != nil {
+				components[name] = saveValue(reflect.ValueOf(data))
+			} else {
+				components[name] = map[string]interface{}{}
+			}
+		}
+	}
+	return components, nil
+}
+
+func saveColor(color color.RGBA) []uint8 {
+	return []uint8{color.R, color.G, color.B, color.A}
+}
+
+//
+// SpriteRender
+//
+
+func saveSpriteRender(spriteRender *c.SpriteRender) (map[string]interface{}, error) {
+	spriteSheet := spriteRender.SpriteSheet
+	switch {
+	case spriteSheet.Name != "":
+		return map[string]interface{}{
+			"sprite_sheet_name": spriteSheet.Name,
+			"sprite_number":     spriteRender.SpriteNumber,
+		}, nil
+	case spriteSheet.FillColor != nil && len(spriteSheet.Sprites) == 1:
+		return map[string]interface{}{
+			"fill": map[string]interface{}{
+				"width":  spriteSheet.Sprites[0].Width,
+				"height": spriteSheet.Sprites[0].Height,
+				"color":  saveColor(*spriteSheet.FillColor),
+			},
+		}, nil
+	}
+	return nil, fmt.Errorf("unable to save sprite sheet without name")
+}
+
+//
+// AnimationControl
+//
+
+// Find the name of an option value, ignoring the empty default alias
+func optionName[T comparable](options map[string]T, value T) string {
+	for name, v := range options {
+		if name != "" && v == value {
+			return name
+		}
+	}
+	return ""
+}
+
+func saveAnimationControl(spriteRender *c.SpriteRender, animationControl *c.AnimationControl) (map[string]interface{}, error) {
+	spriteSheet := spriteRender.SpriteSheet
+
+	// Find animation name, in sorted order for deterministic output
+	animationNames := make([]string, 0, len(spriteSheet.Animations))
+	for name := range spriteSheet.Animations {
+		animationNames = append(animationNames, name)
+	}
+	sort.Strings(animationNames)
+
+	animationName := ""
+	for _, name := range animationNames {
+		if spriteSheet.Animations[name] == animationControl.Animation {
+			animationName = name
+			break
+		}
+	}
+	if animationName == "" {
+		return nil, fmt.Errorf("unable to find animation in sprite sheet '%s'", spriteSheet.Name)
+	}
+
+	endControl := optionName(endControlMap, animationControl.End.Type)
+	animationCommand := optionName(animationCommandMap, animationControl.Command.Type)
+	controlState := optionName(controlStateMap, animationControl.GetState().Type)
+
+	return map[string]interface{}{
+		"sprite_sheet_name":       spriteSheet.Name,
+		"animation_name":          animationName,
+		"end":                     map[string]interface{}{"type": endControl},
+		"command":                 map[string]interface{}{"type": animationCommand, "time": animationControl.Command.Time},
+		"rate_multiplier_minus_1": animationControl.RateMultiplier - 1,
+		"state":                   map[string]interface{}{"type": controlState, "current_time": animationControl.GetState().CurrentTime},
+	}, nil
+}
+
+//
+// Text
+//
+
+func saveText(text *c.Text) (map[string]interface{}, error) {
+	if text.Font == "" {
+		return nil, fmt.Errorf("unable to save font without name")
+	}
+	return map[string]interface{}{
+		"id":   text.ID,
+		"text": text.Text,
+		"font_face": map[string]interface{}{
+			"font":    text.Font,
+			"options": saveValue(reflect.ValueOf(text.FontFaceOptions)),
+		},
+		"color": saveColor(text.Color),
+	}, nil
+}
+
+//
+// Generic values
+//
+
+// Convert a value to TOML data, using TOML tags or lowercase field names as keys and omitting zero fields
+func saveValue(value reflect.Value) interface{} {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+		return saveValue(value.Elem())
+
+	case reflect.Struct:
+		data := make(map[string]interface{})
+		for iField := 0; iField < value.NumField(); iField++ {
+			field := value.Type().Field(iField)
+			tag := field.Tag.Get("toml")
+			if !field.IsExported() || tag == "-" || value.Field(iField).IsZero() {
+				continue
+			}
+			key := strings.Split(tag, ",")[0]
+			if key == "" {
+				key = strings.ToLower(field.Name)
+			}
+			if fieldData := saveValue(value.Field(iField)); fieldData != nil {
+				data[key] = fieldData
+			}
+		}
+		return data
+
+	case reflect.Slice, reflect.Array:
+		data := make([]interface{}, value.Len())
+		for iElem := range data {
+			data[iElem] = saveValue(value.Index(iElem))
+		}
+		return data
+
+	case reflect.Map:
+		data := make(map[string]interface{})
+		iter := value.MapRange()
+		for iter.Next() {
+			data[iter.Key().String()] = saveValue(iter.Value())
+		}
+		return data
+	}
+	return value.Interface()
+}
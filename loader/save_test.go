@@ -0,0 +1,80 @@
+package loader
+
+import (
+	"bytes"
+	"testing"
+	"testing/fstest"
+
+	c "github.com/x-hgg-x/goecsengine/components"
+	w "github.com/x-hgg-x/goecsengine/world"
+
+	ecs "github.com/x-hgg-x/goecs/v2"
+)
+
+const saveTestMetadata = `
+[[entity]]
+
+[entity.components.SpriteRender]
+fill = { width = 10, height = 20, color = [255, 0, 0, 255] }
+
+[entity.components.Transform]
+translation = { x = 40.0, y = 10.0 }
+rotation = 0.5
+depth = 0.25
+
+[entity.components.Name]
+name = "parent"
+
+[entity.components.Tags]
+tags = ["a", "b"]
+
+[[entity.children]]
+
+[entity.children.components.Transform]
+translation = { x = 5.0, y = 0.0 }
+
+[[entity]]
+
+[entity.components.UITransform]
+translation = { x = 100, y = 50 }
+origin = "TopLeft"
+pivot = "TopLeft"
+
+[entity.components.MouseReactive]
+id = "button"
+`
+
+func loadTestEntities(t *testing.T, metadata []byte) w.World {
+	world := w.InitWorld(nil)
+	if _, err := LoadEntitiesE(fstest.MapFS{"entities.toml": {Data: metadata}}, "entities.toml", world); err != nil {
+		t.Fatalf("unable to load entities: %v", err)
+	}
+	return world
+}
+
+func TestSaveEntitiesRoundTrip(t *testing.T) {
+	world := loadTestEntities(t, []byte(saveTestMetadata))
+
+	// Runtime state of mouse reactive entities is not saved
+	world.Manager.Join(world.Components.Engine.MouseReactive).Visit(ecs.Visit(func(entity ecs.Entity) {
+		mouseReactive := world.Components.Engine.MouseReactive.Get(entity).(*c.MouseReactive)
+		mouseReactive.Hovered = true
+		mouseReactive.JustClicked = true
+	}))
+
+	saved, err := SaveEntitiesE(world)
+	if err != nil {
+		t.Fatalf("unable to save entities: %v", err)
+	}
+	if bytes.Contains(saved, []byte("hovered")) || bytes.Contains(saved, []byte("justclicked")) {
+		t.Errorf("expected runtime state of MouseReactive component to be excluded, got:\n%s", saved)
+	}
+
+	reloaded, err := SaveEntitiesE(loadTestEntities(t, saved))
+	if err != nil {
+		t.Fatalf("unable to save reloaded entities: %v", err)
+	}
+	if !bytes.Equal(saved, reloaded) {
+		t.Errorf("expected identical output after reloading saved entities, got:\n%s\nand:\n%s", saved, reloaded)
+	}
+}
@@ -1,19 +1,93 @@
 package loader
 
 import (
+	"fmt"
+	"image"
+	"io/fs"
+	"sort"
+
 	c "github.com/x-hgg-x/goecsengine/components"
 	"github.com/x-hgg-x/goecsengine/utils"
 
-	"github.com/BurntSushi/toml"
+	"github.com/hajimehoshi/ebiten/v2"
 )
 
 type spriteSheetMetadata struct {
 	SpriteSheets map[string]c.SpriteSheet `toml:"sprite_sheet"`
 }
 
-// LoadSpriteSheets loads sprite sheets from a TOML file
-func LoadSpriteSheets(spriteSheetMetadataPath string) map[string]c.SpriteSheet {
+// LoadSpriteSheets loads sprite sheets from a TOML file.
+// Texture image paths are relative to the TOML file, and image formats must be registered by the game (for example by importing image/png).
+func LoadSpriteSheets(fsys fs.FS, spriteSheetMetadataPath string) map[string]c.SpriteSheet {
+	return utils.Try(LoadSpriteSheetsE(fsys, spriteSheetMetadataPath))
+}
+
+// LoadSpriteSheetsE loads sprite sheets from a TOML file, and returns an error if loading fails
+func LoadSpriteSheetsE(fsys fs.FS, spriteSheetMetadataPath string) (map[string]c.SpriteSheet, error) {
 	var spriteSheetMetadata spriteSheetMetadata
-	utils.Try(toml.DecodeFile(spriteSheetMetadataPath, &spriteSheetMetadata))
-	return spriteSheetMetadata.SpriteSheets
+	if _, err := decodeFile(fsys, spriteSheetMetadataPath, &spriteSheetMetadata); err != nil {
+		return nil, fileError(spriteSheetMetadataPath, err)
+	}
+
+	// Load textures in sorted order for deterministic errors
+	names := make([]string, 0, len(spriteSheetMetadata.SpriteSheets))
+	for name := range spriteSheetMetadata.SpriteSheets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Texture images shared by several sprite sheets are loaded once
+	textureImages := make(map[string]*ebiten.Image)
+
+	for _, name := range names {
+		spriteSheet := spriteSheetMetadata.SpriteSheets[name]
+		spriteSheet.Name = name
+
+		// Generate grid sprites
+		if spriteSheet.Grid != nil {
+			gridSprites, err := spriteSheet.Grid.Sprites()
+			if err != nil {
+				return nil, &Error{File: spriteSheetMetadataPath, Field: "sprite_sheet." + name + ".grid", Err: err}
+			}
+			spriteSheet.Sprites = append(gridSprites, spriteSheet.Sprites...)
+		}
+
+		// Check sprite numbers of animations
+		for animationName, animation := range spriteSheet.Animations {
+			for _, spriteNumber := range animation.SpriteNumber {
+				if spriteNumber < 0 || spriteNumber >= len(spriteSheet.Sprites) {
+					return nil, &Error{File: spriteSheetMetadataPath, Field: "sprite_sheet." + name + ".animations." + animationName + ".sprite_number", Err: fmt.Errorf("sprite number %v is out of range", spriteNumber)}
+				}
+			}
+		}
+
+		texturePath := resolvePath(spriteSheetMetadataPath, spriteSheet.Texture.Path)
+		textureImage, ok := textureImages[texturePath]
+		if !ok {
+			var err error
+			if textureImage, err = loadImage(fsys, texturePath); err != nil {
+				return nil, &Error{File: spriteSheetMetadataPath, Field: "sprite_sheet." + name + ".texture_image", Err: err}
+			}
+			textureImages[texturePath] = textureImage
+		}
+		spriteSheet.Texture = c.Texture{Image: textureImage, Path: texturePath}
+
+		spriteSheetMetadata.SpriteSheets[name] = spriteSheet
+	}
+	return spriteSheetMetadata.SpriteSheets, nil
+}
+
+// Load an image from a file system
+func loadImage(fsys fs.FS, imagePath string) (*ebiten.Image, error) {
+	file, err := fsys.Open(imagePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode image '%s': %w", imagePath, err)
+	}
+	return ebiten.NewImageFromImage(img), nil
 }
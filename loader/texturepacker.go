@@ -0,0 +1,57 @@
+package loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+
+	c "github.com/x-hgg-x/goecsengine/components"
+	"github.com/x-hgg-x/goecsengine/utils"
+)
+
+// LoadTexturePackerSpriteSheet loads a sprite sheet from a TexturePacker JSON file, exported with the hash or array format.
+// Sprites are in the order of frames in the file. Trimmed frames are drawn without their trimming offset.
+func LoadTexturePackerSpriteSheet(fsys fs.FS, spriteSheetPath string, name string) c.SpriteSheet {
+	return utils.Try(LoadTexturePackerSpriteSheetE(fsys, spriteSheetPath, name))
+}
+
+// LoadTexturePackerSpriteSheetE loads a sprite sheet from a TexturePacker JSON file, and returns an error if loading fails
+func LoadTexturePackerSpriteSheetE(fsys fs.FS, spriteSheetPath string, name string) (c.SpriteSheet, error) {
+	_, spriteSheet, err := loadJSONSpriteSheet(fsys, spriteSheetPath, name)
+	return spriteSheet, err
+}
+
+// Load a sprite sheet with its texture image from a JSON file
+func loadJSONSpriteSheet(fsys fs.FS, spriteSheetPath string, name string) (jsonSpriteSheet, c.SpriteSheet, error) {
+	content, err := fs.ReadFile(fsys, spriteSheetPath)
+	if err != nil {
+		return jsonSpriteSheet{}, c.SpriteSheet{}, fileError(spriteSheetPath, err)
+	}
+	var data jsonSpriteSheet
+	if err := json.Unmarshal(content, &data); err != nil {
+		return jsonSpriteSheet{}, c.SpriteSheet{}, fileError(spriteSheetPath, err)
+	}
+
+	sprites := make([]c.Sprite, len(data.Frames))
+	for iFrame, frame := range data.Frames {
+		if frame.Rotated {
+			return jsonSpriteSheet{}, c.SpriteSheet{}, &Error{File: spriteSheetPath, Field: "frames." + frame.Filename, Err: fmt.Errorf("rotated frames are not supported")}
+		}
+		if frame.Frame.W <= 0 || frame.Frame.H <= 0 {
+			return jsonSpriteSheet{}, c.SpriteSheet{}, &Error{File: spriteSheetPath, Field: "frames." + frame.Filename, Err: fmt.Errorf("frame dimensions must be positive")}
+		}
+		sprites[iFrame] = c.Sprite{X: frame.Frame.X, Y: frame.Frame.Y, Width: frame.Frame.W, Height: frame.Frame.H}
+	}
+
+	texturePath := resolvePath(spriteSheetPath, data.Meta.Image)
+	textureImage, err := loadImage(fsys, texturePath)
+	if err != nil {
+		return jsonSpriteSheet{}, c.SpriteSheet{}, &Error{File: spriteSheetPath, Field: "meta.image", Err: err}
+	}
+
+	return data, c.SpriteSheet{
+		Name:    name,
+		Texture: c.Texture{Image: textureImage, Path: texturePath},
+		Sprites: sprites,
+	}, nil
+}
@@ -0,0 +1,453 @@
+package loader
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"io/fs"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	c "github.com/x-hgg-x/goecsengine/components"
+	"github.com/x-hgg-x/goecsengine/resources"
+
+	"github.com/BurntSushi/toml"
+	"github.com/golang/freetype/truetype"
+)
+
+// Problem is a problem found when validating metadata files
+type Problem struct {
+	File string
+	// Line of the problem, or 0 if unknown
+	Line int
+	// Dotted key path of the problem, with array indices, or an empty string if unknown
+	Key     string
+	Message string
+}
+
+// String returns the problem with its file, line and key path
+func (p Problem) String() string {
+	location := p.File
+	if p.Line > 0 {
+		location = fmt.Sprintf("%s:%d", p.File, p.Line)
+	}
+	if p.Key != "" {
+		return fmt.Sprintf("%s: %s: %s", location, p.Key, p.Message)
+	}
+	return fmt.Sprintf("%s: %s", location, p.Message)
+}
+
+// ValidateConfig contains the metadata files to validate
+type ValidateConfig struct {
+	SpriteSheets []string
+	Fonts        []string
+	Controls     []string
+	Entities     []string
+	// Registered game components, whose fields are validated
+	Components *c.Components
+	// Names of game components accepted in entity files, whose fields are not validated
+	GameComponents []string
+}
+
+// Validate checks metadata files and returns all problems found.
+// Texture images are not loaded in the game engine, so that validation can run without a game window.
+// Image formats must be registered by the caller (for example by importing image/png).
+func Validate(fsys fs.FS, config ValidateConfig) []Problem {
+	v := &validator{
+		fsys:         fsys,
+		config:       config,
+		spriteSheets: make(map[string]c.SpriteSheet),
+		fonts:        make(map[string]resources.Font),
+		keyLines:     make(map[string]*keyLines),
+	}
+	for _, spriteSheetMetadataPath := range config.SpriteSheets {
+		v.validateSpriteSheets(spriteSheetMetadataPath)
+	}
+	for _, fontPath := range config.Fonts {
+		v.validateFonts(fontPath)
+	}
+	for _, controlsConfigPath := range config.Controls {
+		v.validateControls(controlsConfigPath)
+	}
+	for _, entityMetadataPath := range config.Entities {
+		v.validateEntities(entityMetadataPath)
+	}
+	return v.problems
+}
+
+type validator struct {
+	fsys   fs.FS
+	config ValidateConfig
+	// Sprite sheets and fonts defined in validated files, without loaded images and fonts
+	spriteSheets map[string]c.SpriteSheet
+	fonts        map[string]resources.Font
+	// Lines of keys in decoded files
+	keyLines map[string]*keyLines
+	problems []Problem
+}
+
+func (v *validator) report(file string, key []string, format string, args ...any) {
+	v.problems = append(v.problems, Problem{File: file, Line: v.keyLines[file].line(key), Key: strings.Join(key, "."), Message: fmt.Sprintf(format, args...)})
+}
+
+var errorLineRegexp = regexp.MustCompile(`line (\d+)`)
+
+// Decode a TOML file, and report reading and syntax errors
+func (v *validator) decode(file string, data interface{}) (toml.MetaData, bool) {
+	content, err := fs.ReadFile(v.fsys, file)
+	if err != nil {
+		v.report(file, nil, "%v", err)
+		return toml.MetaData{}, false
+	}
+	metadata, err := toml.Decode(string(content), data)
+	if err != nil {
+		line := 0
+		var parseError toml.ParseError
+		if errors.As(err, &parseError) {
+			line = parseError.Position.Line
+		} else if match := errorLineRegexp.FindStringSubmatch(err.Error()); match != nil {
+			line, _ = strconv.Atoi(match[1])
+		}
+		v.problems = append(v.problems, Problem{File: file, Line: line, Message: err.Error()})
+		return toml.MetaData{}, false
+	}
+	v.keyLines[file] = newKeyLines(string(content), metadata)
+	return metadata, true
+}
+
+// Report each occurrence of keys which were not decoded, except keys decoded by custom unmarshalers.
+// Keys inside an undecoded table are not reported.
+func (v *validator) checkUndecoded(file string, metadata toml.MetaData, ignored func(key toml.Key) bool) {
+	undecoded := make(map[string]bool)
+	for _, key := range metadata.Undecoded() {
+		undecoded[key.String()] = true
+	}
+
+	for _, occurrence := range v.keyLines[file].occurrences {
+		if !undecoded[occurrence.key.String()] || ignored(occurrence.key) {
+			continue
+		}
+		parentUndecoded := false
+		for length := 1; length < len(occurrence.key); length++ {
+			parentUndecoded = parentUndecoded || undecoded[occurrence.key[:length].String()]
+		}
+		if !parentUndecoded {
+			v.problems = append(v.problems, Problem{File: file, Line: occurrence.line, Key: strings.Join(occurrence.path, "."), Message: "unknown key"})
+		}
+	}
+}
+
+// Check if a key is a child of a key matching a pattern, where "*" matches any segment
+func matchKey(key toml.Key, pattern ...string) bool {
+	if len(key) <= len(pattern) {
+		return false
+	}
+	for iSegment, segment := range pattern {
+		if segment != "*" && segment != key[iSegment] {
+			return false
+		}
+	}
+	return true
+}
+
+// Report a loader error, with its field added to the key path
+func (v *validator) reportError(file string, path []string, err error) {
+	var loaderError *Error
+	if errors.As(err, &loaderError) {
+		if loaderError.Field != "" {
+			path = append(append([]string{}, path...), strings.Split(loaderError.Field, ".")...)
+		}
+		err = loaderError.Err
+	}
+	v.report(file, path, "%v", err)
+}
+
+// Get sorted keys of a map
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+//
+// Sprite sheets
+//
+
+func (v *validator) validateSpriteSheets(file string) {
+	var spriteSheetMetadata spriteSheetMetadata
+	metadata, ok := v.decode(file, &spriteSheetMetadata)
+	if !ok {
+		return
+	}
+	v.checkUndecoded(file, metadata, func(key toml.Key) bool {
+		return matchKey(key, "sprite_sheet", "*", "animations", "*")
+	})
+
+	for _, name := range sortedKeys(spriteSheetMetadata.SpriteSheets) {
+		spriteSheet := spriteSheetMetadata.SpriteSheets[name]
+		spriteSheet.Name = name
+		path := []string{"sprite_sheet", name}
+
+		if _, ok := v.spriteSheets[name]; ok {
+			v.report(file, path, "sprite sheet '%s' is already defined", name)
+		}
+
+		// Generate grid sprites
+		gridCount := 0
+		if spriteSheet.Grid != nil {
+			if gridSprites, err := spriteSheet.Grid.Sprites(); err != nil {
+				v.report(file, append(path, "grid"), "%v", err)
+			} else {
+				gridCount = len(gridSprites)
+				spriteSheet.Sprites = append(gridSprites, spriteSheet.Sprites...)
+			}
+		}
+
+		// Check texture image
+		spriteSheet.Texture.Path = resolvePath(file, spriteSheet.Texture.Path)
+		imageConfig, err := v.decodeImageConfig(spriteSheet.Texture.Path)
+		if err != nil {
+			v.report(file, append(path, "texture_image"), "%v", err)
+		}
+
+		// Check sprites
+		for iSprite, sprite := range spriteSheet.Sprites {
+			spritePath := append(append([]string{}, path...), "sprites", strconv.Itoa(iSprite-gridCount))
+			if iSprite < gridCount {
+				spritePath = append(append([]string{}, path...), "grid")
+			}
+			if sprite.Width <= 0 || sprite.Height <= 0 {
+				v.report(file, spritePath, "sprite %v dimensions must be positive", iSprite)
+			} else if err == nil && (sprite.X < 0 || sprite.Y < 0 || sprite.X+sprite.Width > imageConfig.Width || sprite.Y+sprite.Height > imageConfig.Height) {
+				v.report(file, spritePath, "sprite %v is out of bounds of texture image '%s' (%vx%v)", iSprite, spriteSheet.Texture.Path, imageConfig.Width, imageConfig.Height)
+			}
+		}
+
+		// Check animations
+		for _, animationName := range sortedKeys(spriteSheet.Animations) {
+			for _, spriteNumber := range spriteSheet.Animations[animationName].SpriteNumber {
+				if spriteNumber < 0 || spriteNumber >= len(spriteSheet.Sprites) {
+					v.report(file, append(append([]string{}, path...), "animations", animationName, "sprite_number"), "sprite number %v of animation '%s' is out of range", spriteNumber, animationName)
+				}
+			}
+		}
+
+		v.spriteSheets[name] = spriteSheet
+	}
+}
+
+// Decode the dimensions of an image without decoding the entire image
+func (v *validator) decodeImageConfig(imagePath string) (image.Config, error) {
+	file, err := v.fsys.Open(imagePath)
+	if err != nil {
+		return image.Config{}, err
+	}
+	defer file.Close()
+
+	imageConfig, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return image.Config{}, fmt.Errorf("unable to decode image '%s': %w", imagePath, err)
+	}
+	return imageConfig, nil
+}
+
+//
+// Fonts
+//
+
+func (v *validator) validateFonts(file string) {
+	var fontMetadata fontMetadata
+	metadata, ok := v.decode(file, &fontMetadata)
+	if !ok {
+		return
+	}
+	v.checkUndecoded(file, metadata, func(key toml.Key) bool {
+		return matchKey(key, "font", "*")
+	})
+
+	for _, name := range sortedKeys(fontMetadata.Fonts) {
+		font := fontMetadata.Fonts[name]
+		path := []string{"font", name, "font"}
+
+		if _, ok := v.fonts[name]; ok {
+			v.report(file, path, "font '%s' is already defined", name)
+		}
+
+		font.Path = resolvePath(file, font.Path)
+		if fontFile, err := fs.ReadFile(v.fsys, font.Path); err != nil {
+			v.report(file, path, "%v", err)
+		} else if _, err := truetype.Parse(fontFile); err != nil {
+			v.report(file, path, "unable to parse font '%s': %v", font.Path, err)
+		}
+
+		// Fonts are not kept, since only their names are used by entities
+		v.fonts[name] = resources.Font{Path: font.Path}
+	}
+}
+
+//
+// Controls
+//
+
+type controlsMetadata struct {
+	Controls struct {
+		Axes    map[string]toml.Primitive
+		Actions map[string]toml.Primitive
+	} `toml:"controls"`
+}
+
+func (v *validator) validateControls(file string) {
+	var controlsMetadata controlsMetadata
+	metadata, ok := v.decode(file, &controlsMetadata)
+	if !ok {
+		return
+	}
+
+	for _, name := range sortedKeys(controlsMetadata.Controls.Axes) {
+		path := []string{"controls", "axes", name}
+		var axis resources.Axis
+		if err := metadata.PrimitiveDecode(controlsMetadata.Controls.Axes[name], &axis); err != nil {
+			v.report(file, path, "%v", err)
+			continue
+		}
+		switch value := axis.Value.(type) {
+		case nil:
+			v.report(file, path, "unknown or missing axis type")
+		case *resources.Emulated:
+			if value.Pos.Value == nil || value.Neg.Value == nil {
+				v.report(file, path, "emulated axis requires pos and neg buttons")
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(controlsMetadata.Controls.Actions) {
+		path := []string{"controls", "actions", name}
+		var action resources.Action
+		if err := metadata.PrimitiveDecode(controlsMetadata.Controls.Actions[name], &action); err != nil {
+			v.report(file, path, "%v", err)
+			continue
+		}
+		if len(action.Combinations) == 0 {
+			v.report(file, path, "no button combination")
+		}
+		for iCombination, combination := range action.Combinations {
+			for _, button := range combination {
+				if button.Value == nil {
+					v.report(file, append(path, "combinations", strconv.Itoa(iCombination)), "unknown or missing button type")
+				}
+			}
+		}
+	}
+
+	v.checkUndecoded(file, metadata, func(key toml.Key) bool {
+		return matchKey(key, "controls", "axes", "*") || matchKey(key, "controls", "actions", "*", "combinations")
+	})
+}
+
+//
+// Entities
+//
+
+func (v *validator) validateEntities(file string) {
+	var entityMetadata entityMetadata
+	metadata, ok := v.decode(file, &entityMetadata)
+	if !ok {
+		return
+	}
+	v.validateEntityList(file, metadata, entityMetadata.Entities, []string{"entity"})
+
+	// Fields of unregistered game components and unknown components are not decoded
+	v.checkUndecoded(file, metadata, func(key toml.Key) bool {
+		for iSegment := 0; iSegment+1 < len(key); iSegment++ {
+			if key[iSegment] != "components" {
+				continue
+			}
+			_, isEngine := reflect.TypeOf(engineComponentListData{}).FieldByName(key[iSegment+1])
+			_, isRegistered := v.registered(key[iSegment+1])
+			return !isEngine && !isRegistered
+		}
+		return false
+	})
+}
+
+func (v *validator) validateEntityList(file string, metadata toml.MetaData, entities []entity, path []string) {
+	for iEntity, entity := range entities {
+		entityPath := append(append([]string{}, path...), strconv.Itoa(iEntity))
+		componentsPath := append(append([]string{}, entityPath...), "components")
+
+		var engineComponents engineComponentListData
+		engineValue := reflect.ValueOf(&engineComponents).Elem()
+
+		// Decode components
+		for _, name := range sortedKeys(entity.Components) {
+			componentPath := append(append([]string{}, componentsPath...), name)
+
+			var value reflect.Value
+			field := engineValue.FieldByName(name)
+			if field.IsValid() {
+				value = reflect.New(field.Type().Elem())
+			} else if registered, ok := v.registered(name); ok {
+				value = reflect.New(registered.Type)
+			} else if v.isGameComponent(name) {
+				continue
+			} else {
+				v.report(file, componentPath, "unknown component")
+				continue
+			}
+
+			if err := metadata.PrimitiveDecode(entity.Components[name], value.Interface()); err != nil {
+				v.reportError(file, componentPath, componentError(name, err))
+				continue
+			}
+			if field.IsValid() {
+				field.Set(value)
+			}
+		}
+
+		// Check engine components
+		if engineComponents.SpriteRender != nil {
+			if err := checkSpriteRenderData(&v.spriteSheets, engineComponents.SpriteRender); err != nil {
+				v.reportError(file, append(componentsPath, "SpriteRender"), componentError("SpriteRender", err))
+			}
+		}
+		if engineComponents.AnimationControl != nil {
+			if _, err := processAnimationControlData(&v.spriteSheets, engineComponents); err != nil {
+				v.reportError(file, append(componentsPath, "AnimationControl"), componentError("AnimationControl", err))
+			}
+		}
+		if engineComponents.Text != nil {
+			textPath := append(componentsPath, "Text")
+			if _, err := findFont(&v.fonts, engineComponents.Text.FontFace.Font); err != nil {
+				v.reportError(file, textPath, componentError("Text", &Error{Field: "font_face.font", Err: err}))
+			}
+			if _, ok := hintingMap[engineComponents.Text.FontFace.Options.Hinting]; !ok {
+				err := fmt.Errorf("unknown hinting option: '%s'", engineComponents.Text.FontFace.Options.Hinting)
+				v.reportError(file, textPath, componentError("Text", &Error{Field: "font_face.options.hinting", Err: err}))
+			}
+		}
+
+		v.validateEntityList(file, metadata, entity.Children, append(entityPath, "children"))
+	}
+}
+
+func (v *validator) registered(name string) (c.RegisteredComponent, bool) {
+	if v.config.Components == nil {
+		return c.RegisteredComponent{}, false
+	}
+	return v.config.Components.Registered(name)
+}
+
+func (v *validator) isGameComponent(name string) bool {
+	for _, gameComponent := range v.config.GameComponents {
+		if gameComponent == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,466 @@
+package loader
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"time"
+
+	c "github.com/x-hgg-x/goecsengine/components"
+	"github.com/x-hgg-x/goecsengine/resources"
+	w "github.com/x-hgg-x/goecsengine/world"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	ecs "github.com/x-hgg-x/goecs/v2"
+	"golang.org/x/image/font"
+)
+
+// Watcher loads files and reloads them when they are modified while the game is running.
+// Files are polled for modification, so that no platform-specific notification is required.
+type Watcher struct {
+	// Interval is the minimum duration between two polls
+	Interval time.Duration
+
+	fsys     fs.FS
+	lastPoll time.Time
+	files    []*watchedFile
+	entities map[string]*watchedEntities
+	// Groups of loaded entities, updated when entities are deleted
+	loaded         map[ecs.Entity]*entityGroup
+	hookRegistered bool
+}
+
+type watchedFile struct {
+	path string
+	// Modification times of the file and of the files it references
+	modTimes map[string]time.Time
+	// Reload the file and return the paths of the files it references
+	reload func(world w.World) ([]string, error)
+}
+
+type watchedEntities struct {
+	// Groups of entities created from the file, one for each load
+	groups []*entityGroup
+}
+
+// Entities created by a load which have not been deleted
+type entityGroup struct {
+	entities map[ecs.Entity]bool
+}
+
+// NewWatcher creates a new watcher reading files from a file system
+func NewWatcher(fsys fs.FS, interval time.Duration) *Watcher {
+	return &Watcher{Interval: interval, fsys: fsys, entities: make(map[string]*watchedEntities), loaded: make(map[ecs.Entity]*entityGroup)}
+}
+
+// Update polls watched files and reloads modified files.
+// Reloading stops at the first error, and the previous data is kept for the file which failed to reload.
+func (wa *Watcher) Update(world w.World) error {
+	now := time.Now()
+	if now.Sub(wa.lastPoll) < wa.Interval {
+		return nil
+	}
+	wa.lastPoll = now
+
+	for _, file := range wa.files {
+		if !file.modified(wa.fsys) {
+			continue
+		}
+		references, err := file.reload(world)
+		if err != nil {
+			// Update modification times to report the error only once
+			paths := make([]string, 0, len(file.modTimes))
+			for path := range file.modTimes {
+				paths = append(paths, path)
+			}
+			file.modTimes = modTimes(wa.fsys, paths)
+			return fileError(file.path, err)
+		}
+		file.modTimes = modTimes(wa.fsys, append([]string{file.path}, references...))
+	}
+	return nil
+}
+
+// Watch a file with its referenced files
+func (wa *Watcher) watch(path string, references []string, reload func(world w.World) ([]string, error)) {
+	wa.files = append(wa.files, &watchedFile{
+		path:     path,
+		modTimes: modTimes(wa.fsys, append([]string{path}, references...)),
+		reload:   reload,
+	})
+}
+
+// Check if a watched file or one of its referenced files is modified.
+// Missing files are ignored, since editors can replace files when saving.
+func (file *watchedFile) modified(fsys fs.FS) bool {
+	for path, modTime := range file.modTimes {
+		if info, err := fs.Stat(fsys, path); err == nil && !info.ModTime().Equal(modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// Get modification times of files
+func modTimes(fsys fs.FS, paths []string) map[string]time.Time {
+	modTimes := make(map[string]time.Time, len(paths))
+	for _, path := range paths {
+		if info, err := fs.Stat(fsys, path); err == nil {
+			modTimes[path] = info.ModTime()
+		} else {
+			modTimes[path] = time.Time{}
+		}
+	}
+	return modTimes
+}
+
+//
+// Sprite sheets
+//
+
+// LoadSpriteSheets loads sprite sheets from a TOML file, and watches the file and its texture images.
+// When reloaded, sprite sheets are updated in the world resource and in the SpriteRender components referencing them,
+// and animations are updated in place so that AnimationControl components see the new data.
+func (wa *Watcher) LoadSpriteSheets(spriteSheetMetadataPath string) map[string]c.SpriteSheet {
+	spriteSheets := LoadSpriteSheets(wa.fsys, spriteSheetMetadataPath)
+	loadedSpriteSheets := spriteSheets
+
+	wa.watch(spriteSheetMetadataPath, texturePaths(spriteSheets), func(world w.World) ([]string, error) {
+		newSpriteSheets, err := LoadSpriteSheetsE(wa.fsys, spriteSheetMetadataPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := reloadSpriteSheets(world, loadedSpriteSheets, newSpriteSheets); err != nil {
+			return nil, err
+		}
+		loadedSpriteSheets = newSpriteSheets
+		return texturePaths(newSpriteSheets), nil
+	})
+	return spriteSheets
+}
+
+// Get texture image paths of sprite sheets
+func texturePaths(spriteSheets map[string]c.SpriteSheet) []string {
+	paths := []string{}
+	for _, spriteSheet := range spriteSheets {
+		paths = append(paths, spriteSheet.Texture.Path)
+	}
+	return paths
+}
+
+func reloadSpriteSheets(world w.World, spriteSheets map[string]c.SpriteSheet, newSpriteSheets map[string]c.SpriteSheet) error {
+	// Check sprite numbers of entities before modifying anything
+	var err error
+	world.Manager.Join(world.Components.Engine.SpriteRender).Visit(ecs.Visit(func(entity ecs.Entity) {
+		spriteRender := world.Components.Engine.SpriteRender.Get(entity).(*c.SpriteRender)
+		if _, ok := spriteSheets[spriteRender.SpriteSheet.Name]; !ok || err != nil {
+			return
+		}
+		if newSpriteSheet, ok := newSpriteSheets[spriteRender.SpriteSheet.Name]; ok && spriteRender.SpriteNumber >= len(newSpriteSheet.Sprites) {
+			err = fmt.Errorf("sprite number %d of entity %d is out of range in sprite sheet '%s'", spriteRender.SpriteNumber, entity, spriteRender.SpriteSheet.Name)
+		}
+	}))
+	if err != nil {
+		return err
+	}
+
+	// Keep existing animations, which are referenced by AnimationControl components
+	for name, newSpriteSheet := range newSpriteSheets {
+		if spriteSheet, ok := spriteSheets[name]; ok && spriteSheet.Animations != nil {
+			reloadAnimations(spriteSheet.Animations, newSpriteSheet.Animations)
+			newSpriteSheet.Animations = spriteSheet.Animations
+			newSpriteSheets[name] = newSpriteSheet
+		}
+	}
+
+	// Update resource
+	if world.Resources.SpriteSheets != nil {
+		for name := range spriteSheets {
+			delete(*world.Resources.SpriteSheets, name)
+		}
+		for name, newSpriteSheet := range newSpriteSheets {
+			(*world.Resources.SpriteSheets)[name] = newSpriteSheet
+		}
+	}
+
+	// Update entities
+	world.Manager.Join(world.Components.Engine.SpriteRender).Visit(ecs.Visit(func(entity ecs.Entity) {
+		spriteRender := world.Components.Engine.SpriteRender.Get(entity).(*c.SpriteRender)
+		if _, ok := spriteSheets[spriteRender.SpriteSheet.Name]; !ok {
+			return
+		}
+		newSpriteSheet, ok := newSpriteSheets[spriteRender.SpriteSheet.Name]
+		if !ok {
+			// Sprite sheet was removed, entity keeps the previous one
+			return
+		}
+		*spriteRender.SpriteSheet = newSpriteSheet
+		world.MarkChanged(entity, world.Components.Engine.SpriteRender)
+	}))
+
+	// Remove animation controls with removed animations
+	removed := []ecs.Entity{}
+	world.Manager.Join(world.Components.Engine.SpriteRender, world.Components.Engine.AnimationControl).Visit(ecs.Visit(func(entity ecs.Entity) {
+		spriteRender := world.Components.Engine.SpriteRender.Get(entity).(*c.SpriteRender)
+		animationControl := world.Components.Engine.AnimationControl.Get(entity).(*c.AnimationControl)
+		if _, ok := newSpriteSheets[spriteRender.SpriteSheet.Name]; ok && !hasAnimation(spriteRender.SpriteSheet, animationControl.Animation) {
+			removed = append(removed, entity)
+		}
+	}))
+	for _, entity := range removed {
+		world.RemoveComponent(entity, world.Components.Engine.AnimationControl)
+	}
+
+	disposeTextures(spriteSheets, newSpriteSheets)
+	return nil
+}
+
+// Dispose texture images of replaced sprite sheets.
+// Images of removed sprite sheets are kept, since entities can still use them.
+func disposeTextures(spriteSheets map[string]c.SpriteSheet, newSpriteSheets map[string]c.SpriteSheet) {
+	replaced := make(map[*ebiten.Image]bool)
+	kept := make(map[*ebiten.Image]bool)
+	for name, spriteSheet := range spriteSheets {
+		if _, ok := newSpriteSheets[name]; ok {
+			replaced[spriteSheet.Texture.Image] = true
+		} else {
+			kept[spriteSheet.Texture.Image] = true
+		}
+	}
+	for _, newSpriteSheet := range newSpriteSheets {
+		kept[newSpriteSheet.Texture.Image] = true
+	}
+	for image := range replaced {
+		if image != nil && !kept[image] {
+			image.Dispose()
+		}
+	}
+}
+
+// Update animations in place
+func reloadAnimations(animations map[string]*c.Animation, newAnimations map[string]*c.Animation) {
+	for name := range animations {
+		if _, ok := newAnimations[name]; !ok {
+			delete(animations, name)
+		}
+	}
+	for name, newAnimation := range newAnimations {
+		if animation, ok := animations[name]; ok {
+			*animation = *newAnimation
+		} else {
+			animations[name] = newAnimation
+		}
+	}
+}
+
+func hasAnimation(spriteSheet *c.SpriteSheet, animation *c.Animation) bool {
+	for _, a := range spriteSheet.Animations {
+		if a == animation {
+			return true
+		}
+	}
+	return false
+}
+
+//
+// Fonts
+//
+
+// LoadFonts loads fonts from a TOML file, and watches the file and its font files.
+// When reloaded, fonts are updated in the world resource, and font faces of Text components using them are recreated.
+func (wa *Watcher) LoadFonts(fontPath string) map[string]resources.Font {
+	fonts := LoadFonts(wa.fsys, fontPath)
+	loadedFonts := fonts
+
+	wa.watch(fontPath, fontPaths(fonts), func(world w.World) ([]string, error) {
+		newFonts, err := LoadFontsE(wa.fsys, fontPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := reloadFonts(world, loadedFonts, newFonts); err != nil {
+			return nil, err
+		}
+		loadedFonts = newFonts
+		return fontPaths(newFonts), nil
+	})
+	return fonts
+}
+
+// Get font file paths
+func fontPaths(fonts map[string]resources.Font) []string {
+	paths := []string{}
+	for _, font := range fonts {
+		paths = append(paths, font.Path)
+	}
+	return paths
+}
+
+func reloadFonts(world w.World, fonts map[string]resources.Font, newFonts map[string]resources.Font) error {
+	// Create font faces before modifying anything
+	var err error
+	fontFaces := make(map[ecs.Entity]font.Face)
+	world.Manager.Join(world.Components.Engine.Text).Visit(ecs.Visit(func(entity ecs.Entity) {
+		text := world.Components.Engine.Text.Get(entity).(*c.Text)
+		newFont, ok := newFonts[text.Font]
+		if !ok || err != nil {
+			return
+		}
+		fontFace, faceErr := newFontFace(newFont, text.FontFaceOptions)
+		if faceErr != nil {
+			err = fmt.Errorf("unable to create font face for font '%s': %w", text.Font, faceErr)
+			return
+		}
+		fontFaces[entity] = fontFace
+	}))
+	if err != nil {
+		return err
+	}
+
+	// Update resource
+	if world.Resources.Fonts != nil {
+		for name := range fonts {
+			delete(*world.Resources.Fonts, name)
+		}
+		for name, newFont := range newFonts {
+			(*world.Resources.Fonts)[name] = newFont
+		}
+	}
+
+	// Update entities
+	for entity, fontFace := range fontFaces {
+		world.Components.Engine.Text.Get(entity).(*c.Text).FontFace = fontFace
+		world.MarkChanged(entity, world.Components.Engine.Text)
+	}
+	return nil
+}
+
+//
+// Controls
+//
+
+// LoadControls loads controls from a TOML file, and watches the file.
+// When reloaded, controls are updated in the world resource.
+func (wa *Watcher) LoadControls(controlsConfigPath string, axes []string, actions []string) (resources.Controls, resources.InputHandler) {
+	controls, inputHandler := LoadControls(wa.fsys, controlsConfigPath, axes, actions)
+
+	wa.watch(controlsConfigPath, nil, func(world w.World) ([]string, error) {
+		newControls, _, err := LoadControlsE(wa.fsys, controlsConfigPath, axes, actions)
+		if err != nil {
+			return nil, err
+		}
+		if world.Resources.Controls != nil {
+			*world.Resources.Controls = newControls
+		}
+		return nil, nil
+	})
+	return controls, inputHandler
+}
+
+//
+// Entities
+//
+
+// LoadEntities creates entities with components from a TOML file, and watches the file.
+// When reloaded, the entities created from the file which are still alive are deleted, and new entities are created.
+// New entities have the same owner as the deleted entities.
+func (wa *Watcher) LoadEntities(entityMetadataPath string, world w.World) []ecs.Entity {
+	if !wa.hookRegistered {
+		world.RegisterDeleteHook(wa.entityDeleted)
+		wa.hookRegistered = true
+	}
+
+	entities := LoadEntities(wa.fsys, entityMetadataPath, world)
+	group := wa.newEntityGroup(entities)
+
+	if watched, ok := wa.entities[entityMetadataPath]; ok {
+		watched.groups = append(aliveGroups(watched.groups), group)
+		return entities
+	}
+
+	watched := &watchedEntities{groups: []*entityGroup{group}}
+	wa.entities[entityMetadataPath] = watched
+
+	wa.watch(entityMetadataPath, nil, func(world w.World) ([]string, error) {
+		return nil, wa.reloadEntities(world, entityMetadataPath, watched)
+	})
+	return entities
+}
+
+func (wa *Watcher) reloadEntities(world w.World, entityMetadataPath string, watched *watchedEntities) error {
+	// Check the file before deleting entities
+	entityMetadataContent, err := fs.ReadFile(wa.fsys, entityMetadataPath)
+	if err != nil {
+		return err
+	}
+	if _, err := LoadEntityComponentsE(entityMetadataContent, world); err != nil {
+		return err
+	}
+
+	groups := []*entityGroup{}
+	for _, group := range aliveGroups(watched.groups) {
+		// Deleting a group can delete entities of other groups
+		aliveEntities := world.Manager.Join()
+		entities := []ecs.Entity{}
+		for entity := range group.entities {
+			if aliveEntities.Contains(int(entity)) {
+				entities = append(entities, entity)
+			}
+		}
+		if len(entities) == 0 {
+			continue
+		}
+		sort.Slice(entities, func(i, j int) bool { return entities[i] < entities[j] })
+
+		// Watched files are reloaded outside of the state machine update, so the owner of deleted entities is kept
+		var owner *c.Owner
+		for _, entity := range entities {
+			if entity.HasComponent(world.Components.Engine.Owner) {
+				entityOwner := *world.Components.Engine.Owner.Get(entity).(*c.Owner)
+				owner = &entityOwner
+				break
+			}
+		}
+		world.DeleteEntities(entities...)
+
+		// Components are loaded for each group, since they cannot be shared between entities
+		entityComponentList, err := LoadEntityComponentsE(entityMetadataContent, world)
+		if err != nil {
+			watched.groups = append(groups, aliveGroups(watched.groups)...)
+			return err
+		}
+		previousOwner := world.Resources.CurrentOwner
+		world.Resources.CurrentOwner = owner
+		groups = append(groups, wa.newEntityGroup(AddEntities(world, entityComponentList)))
+		world.Resources.CurrentOwner = previousOwner
+	}
+	watched.groups = groups
+	return nil
+}
+
+func (wa *Watcher) newEntityGroup(entities []ecs.Entity) *entityGroup {
+	group := &entityGroup{entities: make(map[ecs.Entity]bool, len(entities))}
+	for _, entity := range entities {
+		group.entities[entity] = true
+		wa.loaded[entity] = group
+	}
+	return group
+}
+
+// Remove a deleted entity from its group, so that entities created later with the same identifier are not reloaded
+func (wa *Watcher) entityDeleted(world w.World, entity ecs.Entity) {
+	if group, ok := wa.loaded[entity]; ok {
+		delete(group.entities, entity)
+		delete(wa.loaded, entity)
+	}
+}
+
+// Remove groups with all entities deleted
+func aliveGroups(groups []*entityGroup) []*entityGroup {
+	alive := []*entityGroup{}
+	for _, group := range groups {
+		if len(group.entities) > 0 {
+			alive = append(alive, group)
+		}
+	}
+	return alive
+}
@@ -0,0 +1,72 @@
+package resources
+
+import (
+	ecs "github.com/x-hgg-x/goecs/v2"
+)
+
+// CommandType is a deferred command type
+type CommandType int
+
+const (
+	// CommandSpawn creates an entity, owned by the current state of the state machine if any
+	CommandSpawn CommandType = iota
+	// CommandDespawn deletes an entity
+	CommandDespawn
+	// CommandAddComponent adds a component to an entity
+	CommandAddComponent
+	// CommandRemoveComponent removes a component from an entity
+	CommandRemoveComponent
+)
+
+// Command is a deferred structural change
+type Command struct {
+	// Command type
+	Type CommandType
+	// Target entity
+	Entity ecs.Entity
+	// Target component, used only with CommandAddComponent and CommandRemoveComponent
+	Component ecs.DataComponent
+	// Component data, used only with CommandAddComponent
+	Data interface{}
+}
+
+// Commands is a queue of structural changes, which are safe to request while iterating over entities.
+// Commands are applied in order by the state machine after each system.
+type Commands struct {
+	manager  *ecs.Manager
+	commands []Command
+}
+
+// NewCommands creates a new command queue
+func NewCommands(manager *ecs.Manager) *Commands {
+	return &Commands{manager: manager}
+}
+
+// Spawn reserves a new entity, which is created when commands are applied
+func (c *Commands) Spawn() ecs.Entity {
+	entity := c.manager.NewEntity()
+	c.commands = append(c.commands, Command{Type: CommandSpawn, Entity: entity})
+	return entity
+}
+
+// Despawn queues the deletion of an entity
+func (c *Commands) Despawn(entity ecs.Entity) {
+	c.commands = append(c.commands, Command{Type: CommandDespawn, Entity: entity})
+}
+
+// AddComponent queues the addition of a component to an entity
+func (c *Commands) AddComponent(entity ecs.Entity, component ecs.DataComponent, data interface{}) {
+	c.commands = append(c.commands, Command{Type: CommandAddComponent, Entity: entity, Component: component, Data: data})
+}
+
+// RemoveComponent queues the removal of a component from an entity
+func (c *Commands) RemoveComponent(entity ecs.Entity, component ecs.DataComponent) {
+	c.commands = append(c.commands, Command{Type: CommandRemoveComponent, Entity: entity, Component: component})
+}
+
+// Take returns the queued commands and empties the queue
+func (c *Commands) Take() []Command {
+	commands := c.commands
+	c.commands = nil
+	return commands
+}
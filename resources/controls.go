@@ -47,13 +47,24 @@ type ControllerButton struct {
 
 // UnmarshalTOML fills structure fields from TOML data
 func (b *ControllerButton) UnmarshalTOML(i interface{}) error {
-	data := i.(map[string]interface{})
-	if gamepadButton, ok := utils.GamepadButtonMap[data["button"].(string)]; ok {
-		b.ID = ebiten.GamepadID(data["id"].(int64))
+	data, ok := i.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("gamepad button must be a table")
+	}
+	buttonName, ok := data["button"].(string)
+	if !ok {
+		return fmt.Errorf("gamepad button field must be a string")
+	}
+	id, ok := data["id"].(int64)
+	if !ok {
+		return fmt.Errorf("gamepad id field must be an integer")
+	}
+	if gamepadButton, ok := utils.GamepadButtonMap[buttonName]; ok {
+		b.ID = ebiten.GamepadID(id)
 		b.GamepadButton = gamepadButton
 		return nil
 	}
-	return fmt.Errorf("unknown gamepad button: '%s'", data["button"].(string))
+	return fmt.Errorf("unknown gamepad button: '%s'", buttonName)
 }
 
 type button struct {
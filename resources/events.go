@@ -0,0 +1,113 @@
+package resources
+
+import (
+	"reflect"
+
+	ecs "github.com/x-hgg-x/goecs/v2"
+)
+
+// AnimationDoneEvent is published by the animation system when an animation is done
+type AnimationDoneEvent struct {
+	Entity ecs.Entity
+}
+
+// EntityClickedEvent is published by the UI system when a mouse reactive entity is clicked
+type EntityClickedEvent struct {
+	Entity ecs.Entity
+	ID     string
+}
+
+// EntityHoveredEvent is published by the UI system when the mouse starts or stops hovering a mouse reactive entity
+type EntityHoveredEvent struct {
+	Entity  ecs.Entity
+	ID      string
+	Hovered bool
+}
+
+// HierarchyCycleEvent is published by the hierarchy system when a cycle is found in the entity hierarchy.
+// The Parent component of the entity is removed to break the cycle.
+type HierarchyCycleEvent struct {
+	Entity ecs.Entity
+}
+
+// Events contains typed event queues.
+// Events are cleared by the state machine two updates after their publication,
+// so that they can be read by all systems executed before and after the publishing system.
+type Events struct {
+	queues map[reflect.Type]eventQueue
+}
+
+type eventQueue interface {
+	update()
+}
+
+type typedEventQueue[T any] struct {
+	previous []T
+	current  []T
+	// Sequence number of the first event in the previous buffer
+	start int
+}
+
+func (q *typedEventQueue[T]) update() {
+	q.start += len(q.previous)
+	q.previous, q.current = q.current, q.previous[:0]
+}
+
+// NewEvents creates a new event resource
+func NewEvents() *Events {
+	return &Events{queues: make(map[reflect.Type]eventQueue)}
+}
+
+// Update clears events published before the last update
+func (e *Events) Update() {
+	for _, queue := range e.queues {
+		queue.update()
+	}
+}
+
+// Publish publishes a new event
+func Publish[T any](events *Events, event T) {
+	queue := getEventQueue[T](events, true)
+	queue.current = append(queue.current, event)
+}
+
+// EventReader reads events of a type.
+// Each event is returned only once by a reader.
+type EventReader[T any] struct {
+	// Sequence number of the next event to read
+	next int
+}
+
+// Read returns events published since the last read
+func (r *EventReader[T]) Read(events *Events) []T {
+	queue := getEventQueue[T](events, false)
+	if queue == nil {
+		return nil
+	}
+
+	newEvents := []T{}
+	sequence := queue.start
+	for _, buffer := range [][]T{queue.previous, queue.current} {
+		for _, event := range buffer {
+			if sequence >= r.next {
+				newEvents = append(newEvents, event)
+			}
+			sequence++
+		}
+	}
+	r.next = sequence
+	return newEvents
+}
+
+func getEventQueue[T any](events *Events, create bool) *typedEventQueue[T] {
+	eventType := reflect.TypeOf((*T)(nil)).Elem()
+	if queue, ok := events.queues[eventType]; ok {
+		return queue.(*typedEventQueue[T])
+	}
+	if !create {
+		return nil
+	}
+	queue := &typedEventQueue[T]{}
+	events.queues[eventType] = queue
+	return queue
+}
@@ -1,9 +1,7 @@
 package resources
 
 import (
-	"os"
-
-	"github.com/x-hgg-x/goecsengine/utils"
+	"fmt"
 
 	"github.com/golang/freetype/truetype"
 )
@@ -11,11 +9,23 @@ import (
 // Font structure
 type Font struct {
 	Font *truetype.Font
+	// Font file path in the file system used for loading the fonts.
+	// It is relative to the font metadata file when decoded, and resolved by the font loader.
+	Path string
 }
 
-// UnmarshalTOML fills structure fields from TOML data
+// UnmarshalTOML fills structure fields from TOML data.
+// The font is loaded from its path by the font loader.
 func (f *Font) UnmarshalTOML(i interface{}) error {
-	fontFile := utils.Try(os.ReadFile(i.(map[string]interface{})["font"].(string)))
-	f.Font = utils.Try(truetype.Parse(fontFile))
+	data, ok := i.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("font must be a table")
+	}
+	fontPath, ok := data["font"].(string)
+	if !ok {
+		return fmt.Errorf("font field must be a string")
+	}
+
+	f.Path = fontPath
 	return nil
 }
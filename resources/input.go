@@ -0,0 +1,61 @@
+package resources
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// InputSource provides the state of input devices
+type InputSource interface {
+	IsKeyPressed(key ebiten.Key) bool
+	IsKeyJustPressed(key ebiten.Key) bool
+	IsMouseButtonPressed(mouseButton ebiten.MouseButton) bool
+	IsMouseButtonJustPressed(mouseButton ebiten.MouseButton) bool
+	IsGamepadButtonPressed(id ebiten.GamepadID, gamepadButton ebiten.GamepadButton) bool
+	IsGamepadButtonJustPressed(id ebiten.GamepadID, gamepadButton ebiten.GamepadButton) bool
+	GamepadAxisValue(id ebiten.GamepadID, axis int) float64
+	CursorPosition() (x, y int)
+}
+
+// EbitenInput is the default input source, reading input devices with Ebitengine
+type EbitenInput struct{}
+
+// IsKeyPressed returns true if the key is pressed
+func (EbitenInput) IsKeyPressed(key ebiten.Key) bool {
+	return ebiten.IsKeyPressed(key)
+}
+
+// IsKeyJustPressed returns true if the key has just been pressed
+func (EbitenInput) IsKeyJustPressed(key ebiten.Key) bool {
+	return inpututil.IsKeyJustPressed(key)
+}
+
+// IsMouseButtonPressed returns true if the mouse button is pressed
+func (EbitenInput) IsMouseButtonPressed(mouseButton ebiten.MouseButton) bool {
+	return ebiten.IsMouseButtonPressed(mouseButton)
+}
+
+// IsMouseButtonJustPressed returns true if the mouse button has just been pressed
+func (EbitenInput) IsMouseButtonJustPressed(mouseButton ebiten.MouseButton) bool {
+	return inpututil.IsMouseButtonJustPressed(mouseButton)
+}
+
+// IsGamepadButtonPressed returns true if the gamepad button is pressed
+func (EbitenInput) IsGamepadButtonPressed(id ebiten.GamepadID, gamepadButton ebiten.GamepadButton) bool {
+	return ebiten.IsGamepadButtonPressed(id, gamepadButton)
+}
+
+// IsGamepadButtonJustPressed returns true if the gamepad button has just been pressed
+func (EbitenInput) IsGamepadButtonJustPressed(id ebiten.GamepadID, gamepadButton ebiten.GamepadButton) bool {
+	return inpututil.IsGamepadButtonJustPressed(id, gamepadButton)
+}
+
+// GamepadAxisValue returns the gamepad axis value
+func (EbitenInput) GamepadAxisValue(id ebiten.GamepadID, axis int) float64 {
+	return ebiten.GamepadAxisValue(id, axis)
+}
+
+// CursorPosition returns the cursor position
+func (EbitenInput) CursorPosition() (x, y int) {
+	return ebiten.CursorPosition()
+}
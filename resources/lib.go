@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"reflect"
+
 	"github.com/x-hgg-x/goecsengine/components"
 
 	"github.com/hajimehoshi/ebiten/v2/audio"
@@ -11,15 +13,21 @@ type Resources struct {
 	ScreenDimensions *ScreenDimensions
 	Controls         *Controls
 	InputHandler     *InputHandler
+	InputSource      InputSource
 	SpriteSheets     *map[string]components.SpriteSheet
 	Fonts            *map[string]Font
+	Prefabs          *map[string]Prefab
 	AudioContext     *audio.Context
 	AudioPlayers     *map[string]*audio.Player
-	Prefabs          interface{}
-	Game             interface{}
+	Time             *Time
+	Events           *Events
+	Commands         *Commands
+	CurrentOwner     *components.Owner
+
+	typed map[reflect.Type]interface{}
 }
 
 // InitResources initializes resources
 func InitResources() *Resources {
-	return &Resources{Controls: &Controls{}, InputHandler: &InputHandler{}}
+	return &Resources{Controls: &Controls{}, InputHandler: &InputHandler{}, InputSource: EbitenInput{}, Time: NewTime(), Events: NewEvents(), typed: make(map[reflect.Type]interface{})}
 }
@@ -0,0 +1,5 @@
+package resources
+
+// Prefab is an entity template, with the same fields as an entity table in a TOML entity file.
+// Prefab inheritance is already resolved.
+type Prefab map[string]interface{}
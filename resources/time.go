@@ -0,0 +1,60 @@
+package resources
+
+import (
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Time contains timing data, updated by the state machine on every frame
+type Time struct {
+	// Delta is the scaled time elapsed since the last update in seconds, equal to zero when time is paused
+	Delta float64
+	// UnscaledDelta is the real time elapsed since the last update in seconds
+	UnscaledDelta float64
+	// Elapsed is the scaled time elapsed since the first update in seconds
+	Elapsed float64
+	// Frame is the number of updates
+	Frame int
+	// Scale is the time scale applied to delta time
+	Scale float64
+	// Paused stops scaled time
+	Paused bool
+	// FixedDelta forces the unscaled delta time in seconds when not zero, which is useful for deterministic updates
+	FixedDelta float64
+	// MaxDelta limits the unscaled delta time in seconds when not zero, avoiding large steps after a freeze
+	MaxDelta float64
+	// Time of the last update
+	lastUpdate time.Time
+}
+
+// NewTime creates a new time resource with a time scale of 1
+func NewTime() *Time {
+	return &Time{Scale: 1, MaxDelta: 0.25}
+}
+
+// Advance updates timing data from the current time
+func (t *Time) Advance(now time.Time) {
+	unscaledDelta := t.FixedDelta
+	if unscaledDelta == 0 {
+		if t.lastUpdate.IsZero() {
+			unscaledDelta = 1 / float64(ebiten.DefaultTPS)
+		} else {
+			unscaledDelta = now.Sub(t.lastUpdate).Seconds()
+		}
+		if t.MaxDelta > 0 {
+			unscaledDelta = math.Min(unscaledDelta, t.MaxDelta)
+		}
+	}
+	t.lastUpdate = now
+
+	t.UnscaledDelta = unscaledDelta
+	if t.Paused {
+		t.Delta = 0
+	} else {
+		t.Delta = unscaledDelta * t.Scale
+	}
+	t.Elapsed += t.Delta
+	t.Frame++
+}
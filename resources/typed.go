@@ -0,0 +1,42 @@
+package resources
+
+import (
+	"fmt"
+	"reflect"
+)
+
+func typeKey[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// Insert inserts a game resource, replacing the existing resource with the same type
+func Insert[T any](resources *Resources, resource T) {
+	if resources.typed == nil {
+		resources.typed = make(map[reflect.Type]interface{})
+	}
+	resources.typed[typeKey[T]()] = resource
+}
+
+// Get returns the game resource with the specified type, or an error if it does not exist
+func Get[T any](resources *Resources) (T, error) {
+	resource, ok := TryGet[T](resources)
+	if !ok {
+		return resource, fmt.Errorf("no resource with type '%s' has been inserted", typeKey[T]())
+	}
+	return resource, nil
+}
+
+// TryGet returns the game resource with the specified type if it exists
+func TryGet[T any](resources *Resources) (T, bool) {
+	resource, ok := resources.typed[typeKey[T]()]
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return resource.(T), true
+}
+
+// Remove removes the game resource with the specified type
+func Remove[T any](resources *Resources) {
+	delete(resources.typed, typeKey[T]())
+}
@@ -0,0 +1,178 @@
+package states
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// EffectType is a transition effect type
+type EffectType int
+
+const (
+	// EffectNone performs an instant transition
+	EffectNone EffectType = iota
+	// EffectFade fades the outgoing scene to a color, then fades the color to the incoming scene.
+	// The transition is applied at the middle of the effect, when the screen is filled with the color.
+	EffectFade
+	// EffectCrossfade blends the outgoing scene into the incoming scene.
+	// The transition is applied at the start of the effect.
+	EffectCrossfade
+	// EffectSlide moves the incoming scene into the screen, pushing the outgoing scene.
+	// The transition is applied at the start of the effect.
+	EffectSlide
+	// EffectWipe progressively reveals the incoming scene over the outgoing scene.
+	// The transition is applied at the start of the effect.
+	EffectWipe
+)
+
+// EffectDirection is the direction of the incoming scene for slide and wipe effects
+type EffectDirection int
+
+const (
+	// EffectDirectionLeft moves the incoming scene from the right edge to the left
+	EffectDirectionLeft EffectDirection = iota
+	// EffectDirectionRight moves the incoming scene from the left edge to the right
+	EffectDirectionRight
+	// EffectDirectionUp moves the incoming scene from the bottom edge to the top
+	EffectDirectionUp
+	// EffectDirectionDown moves the incoming scene from the top edge to the bottom
+	EffectDirectionDown
+)
+
+// Effect is a visual transition effect.
+// The outgoing scene is frozen at its last frame during the effect.
+type Effect struct {
+	// Effect type
+	Type EffectType
+	// Effect duration in seconds
+	Duration float64
+	// Color used by the fade effect
+	Color color.RGBA
+	// Direction used by the slide and wipe effects
+	Direction EffectDirection
+}
+
+type runningEffect struct {
+	effect     Effect
+	transition Transition
+	elapsed    float64
+	applied    bool
+	captured   bool
+}
+
+// Get the effect time when the transition is applied
+func (e *runningEffect) applyTime() float64 {
+	if e.effect.Type == EffectFade {
+		return e.effect.Duration / 2
+	}
+	return 0
+}
+
+// Get the effect progress between 0 and 1
+func (e *runningEffect) progress() float64 {
+	return math.Min(math.Max(e.elapsed/e.effect.Duration, 0), 1)
+}
+
+// Draw the effect on the screen, from the outgoing and incoming scene images
+func (e *runningEffect) draw(screen, from, to *ebiten.Image, fill *ebiten.Image) {
+	screenWidth, screenHeight := screen.Size()
+	width, height := float64(screenWidth), float64(screenHeight)
+	progress := e.progress()
+
+	if !e.captured {
+		from = nil
+	}
+	if !e.applied {
+		to = nil
+	}
+
+	drawScene := func(scene *ebiten.Image, op *ebiten.DrawImageOptions) {
+		if scene != nil {
+			screen.DrawImage(scene, op)
+		}
+	}
+
+	switch e.effect.Type {
+	case EffectFade:
+		alpha := 0.0
+		if e.applied {
+			drawScene(to, nil)
+			alpha = 2 * (1 - progress)
+		} else {
+			drawScene(from, nil)
+			alpha = 2 * progress
+		}
+
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(width, height)
+		op.ColorM.Scale(float64(e.effect.Color.R)/255, float64(e.effect.Color.G)/255, float64(e.effect.Color.B)/255, math.Min(alpha, 1)*float64(e.effect.Color.A)/255)
+		screen.DrawImage(fill, op)
+
+	case EffectCrossfade:
+		drawScene(to, nil)
+		op := &ebiten.DrawImageOptions{}
+		op.ColorM.Scale(1, 1, 1, 1-progress)
+		drawScene(from, op)
+
+	case EffectSlide:
+		dirX, dirY := e.effect.Direction.vector()
+		fromOp := &ebiten.DrawImageOptions{}
+		fromOp.GeoM.Translate(dirX*progress*width, dirY*progress*height)
+		drawScene(from, fromOp)
+		toOp := &ebiten.DrawImageOptions{}
+		toOp.GeoM.Translate(-dirX*(1-progress)*width, -dirY*(1-progress)*height)
+		drawScene(to, toOp)
+
+	case EffectWipe:
+		drawScene(from, nil)
+		if to != nil {
+			var rect image.Rectangle
+			switch e.effect.Direction {
+			case EffectDirectionLeft:
+				rect = image.Rect(int((1-progress)*width), 0, screenWidth, screenHeight)
+			case EffectDirectionRight:
+				rect = image.Rect(0, 0, int(progress*width), screenHeight)
+			case EffectDirectionUp:
+				rect = image.Rect(0, int((1-progress)*height), screenWidth, screenHeight)
+			case EffectDirectionDown:
+				rect = image.Rect(0, 0, screenWidth, int(progress*height))
+			}
+			if !rect.Empty() {
+				op := &ebiten.DrawImageOptions{}
+				op.GeoM.Translate(float64(rect.Min.X), float64(rect.Min.Y))
+				screen.DrawImage(to.SubImage(rect).(*ebiten.Image), op)
+			}
+		}
+	}
+}
+
+// Get the screen direction vector of the incoming scene movement
+func (d EffectDirection) vector() (x, y float64) {
+	switch d {
+	case EffectDirectionLeft:
+		return -1, 0
+	case EffectDirectionRight:
+		return 1, 0
+	case EffectDirectionUp:
+		return 0, -1
+	case EffectDirectionDown:
+		return 0, 1
+	}
+	return 0, 0
+}
+
+// Get an offscreen image with the same size as the screen
+func offscreenImage(img *ebiten.Image, screen *ebiten.Image) *ebiten.Image {
+	screenWidth, screenHeight := screen.Size()
+	if img != nil {
+		if width, height := img.Size(); width == screenWidth && height == screenHeight {
+			img.Clear()
+			return img
+		}
+		img.Dispose()
+	}
+	return ebiten.NewImage(screenWidth, screenHeight)
+}
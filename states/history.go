@@ -0,0 +1,95 @@
+package states
+
+import (
+	"errors"
+	"fmt"
+
+	w "github.com/x-hgg-x/goecsengine/world"
+)
+
+// MaxHistorySize is the maximum number of transitions kept in the state machine history
+const MaxHistorySize = 64
+
+// ErrInvalidTransition is returned by the state machine update when a transition is invalid
+var ErrInvalidTransition = errors.New("invalid transition")
+
+// TransitionRecord describes a transition applied to the state stack
+type TransitionRecord struct {
+	// Applied transition
+	Transition Transition
+	// State stack before the transition
+	OldStack []State
+	// State stack after the transition
+	NewStack []State
+	// Frame number when the transition was applied
+	Frame int
+}
+
+// TransitionObserver contains functions executed when a transition is applied
+type TransitionObserver struct {
+	// Executed before the transition, with the current stack and the expected new stack
+	Before func(world w.World, record TransitionRecord)
+	// Executed after the transition
+	After func(world w.World, record TransitionRecord)
+}
+
+// AddObserver adds a transition observer to the state machine
+func (sm *StateMachine) AddObserver(observer TransitionObserver) {
+	sm.observers = append(sm.observers, observer)
+}
+
+// History returns the last applied transitions, from the oldest to the most recent
+func (sm *StateMachine) History() []TransitionRecord {
+	return append([]TransitionRecord{}, sm.history...)
+}
+
+// Check that a transition can be applied to the state stack
+func validateTransition(transition Transition) error {
+	for _, state := range transition.NewStates {
+		if state == nil {
+			return fmt.Errorf("%w: new states must not be nil", ErrInvalidTransition)
+		}
+	}
+
+	switch transition.Type {
+	case TransNone, TransPop, TransReplace, TransQuit:
+		return nil
+	case TransPush:
+		if len(transition.NewStates) < 1 {
+			return fmt.Errorf("%w: push transition requires at least one new state", ErrInvalidTransition)
+		}
+		return nil
+	case TransSwitch:
+		if len(transition.NewStates) != 1 {
+			return fmt.Errorf("%w: switch transition accepts only one new state, got %d", ErrInvalidTransition, len(transition.NewStates))
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: unknown transition type: %v", ErrInvalidTransition, transition.Type)
+}
+
+// Compute the state stack after a transition
+func (sm *StateMachine) nextStack(transition Transition) []State {
+	stack := append([]State{}, sm.states...)
+	switch transition.Type {
+	case TransPop:
+		stack = stack[:len(stack)-1]
+	case TransPush:
+		stack = append(stack, transition.NewStates...)
+	case TransSwitch:
+		stack[len(stack)-1] = transition.NewStates[0]
+	case TransReplace:
+		stack = append([]State{}, transition.NewStates...)
+	case TransQuit:
+		stack = []State{}
+	}
+	return stack
+}
+
+// Add a transition record to the history
+func (sm *StateMachine) recordTransition(record TransitionRecord) {
+	sm.history = append(sm.history, record)
+	if len(sm.history) > MaxHistorySize {
+		sm.history = append([]TransitionRecord{}, sm.history[len(sm.history)-MaxHistorySize:]...)
+	}
+}
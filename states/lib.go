@@ -1,13 +1,11 @@
 package states
 
 import (
-	"os"
+	"errors"
+	"image/color"
+	"time"
 
-	a "github.com/x-hgg-x/goecsengine/systems/animation"
-	i "github.com/x-hgg-x/goecsengine/systems/input"
-	s "github.com/x-hgg-x/goecsengine/systems/sprite"
-	u "github.com/x-hgg-x/goecsengine/systems/ui"
-	"github.com/x-hgg-x/goecsengine/utils"
+	c "github.com/x-hgg-x/goecsengine/components"
 	w "github.com/x-hgg-x/goecsengine/world"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -31,10 +29,31 @@ const (
 	TransQuit
 )
 
+// ErrQuit is returned by the state machine update when the state stack becomes empty.
+// It should be returned by the game Update function to stop the game loop.
+var ErrQuit = errors.New("state machine quit")
+
+// StatePushedEvent is published when a state is added to the stack
+type StatePushedEvent struct {
+	State State
+}
+
+// StatePoppedEvent is published when a state is removed from the stack
+type StatePoppedEvent struct {
+	State State
+}
+
 // Transition is a state transition
 type Transition struct {
 	Type      TransType
 	NewStates []State
+	// Optional visual effect
+	Effect Effect
+}
+
+// Check if the transition has a visual effect
+func (t Transition) hasEffect() bool {
+	return t.Type != TransNone && t.Effect.Type != EffectNone && t.Effect.Duration > 0
 }
 
 // State is a game state
@@ -51,77 +70,265 @@ type State interface {
 	Update(world w.World) Transition
 }
 
+// ShadowUpdateState is implemented by states which keep updating when other states are pushed over them
+type ShadowUpdateState interface {
+	State
+	// Executed on every frame when the state is paused, before the active state update
+	ShadowUpdate(world w.World)
+}
+
+// ShadowDrawState is implemented by states which keep drawing when other states are pushed over them
+type ShadowDrawState interface {
+	State
+	// Executed on every frame when the state is paused, before the active state drawing systems
+	ShadowDraw(world w.World, screen *ebiten.Image)
+}
+
 // StateMachine contains a stack of states.
 // Only the top state is active.
 type StateMachine struct {
+	// Pipeline contains the systems executed for states which don't define their own pipeline
+	Pipeline Pipeline
+	// OnQuit is executed before the state machine stops, after all states have been stopped
+	OnQuit         func(world w.World)
 	states         []State
+	owners         []c.Owner
+	lastOwnerID    int
 	lastTransition Transition
+	effect         *runningEffect
+	fromImage      *ebiten.Image
+	toImage        *ebiten.Image
+	fillImage      *ebiten.Image
+	captured       bool
+	observers      []TransitionObserver
+	history        []TransitionRecord
 }
 
 // Init creates a new state machine with an initial state
 func Init(s State, world w.World) StateMachine {
-	s.OnStart(world)
-	return StateMachine{[]State{s}, Transition{TransNone, []State{}}}
+	sm := StateMachine{Pipeline: DefaultPipeline(), states: []State{s}, lastTransition: Transition{Type: TransNone, NewStates: []State{}}}
+	sm.owners = []c.Owner{sm.startState(world, s)}
+	return sm
 }
 
-// Update updates the state machine
-func (sm *StateMachine) Update(world w.World) {
-	switch sm.lastTransition.Type {
-	case TransPop:
-		sm._Pop(world)
-	case TransPush:
-		sm._Push(world, sm.lastTransition.NewStates)
-	case TransSwitch:
-		sm._Switch(world, sm.lastTransition.NewStates)
-	case TransReplace:
-		sm._Replace(world, sm.lastTransition.NewStates)
-	case TransQuit:
-		sm._Quit(world)
+// Update updates the state machine.
+// It returns ErrQuit when there are no more states in the stack, or an error wrapping ErrInvalidTransition when a transition is invalid.
+func (sm *StateMachine) Update(world w.World) error {
+	// Update time and clear old events
+	world.Resources.Time.Advance(time.Now())
+	world.Resources.Events.Update()
+
+	// Update running transition effect
+	if sm.effect != nil {
+		if sm.lastTransition.Type != TransNone {
+			// A new transition interrupts the running effect
+			sm.stopEffect(world)
+		} else {
+			sm.updateEffect(world)
+		}
+	}
+
+	// Process last transition
+	if sm.lastTransition.Type != TransNone {
+		if err := validateTransition(sm.lastTransition); err != nil {
+			// The invalid transition is dropped, so that the error is reported only once
+			sm.lastTransition = Transition{}
+			return err
+		}
+		if sm.lastTransition.hasEffect() {
+			sm.startEffect(world, sm.lastTransition)
+		} else {
+			sm.applyTransition(world, sm.lastTransition)
+		}
+		sm.lastTransition = Transition{}
 	}
 
 	if len(sm.states) < 1 {
-		os.Exit(0)
+		return sm.quit(world)
+	}
+
+	// The outgoing scene is frozen until the transition is applied
+	if sm.effect != nil && !sm.effect.applied {
+		return nil
 	}
 
+	pipeline := sm.activePipeline()
+
+	// Entities loaded during the update are owned by the active state
+	owner := sm.owners[len(sm.owners)-1]
+	world.Resources.CurrentOwner = &owner
+
 	// Run pre-game systems
-	i.InputSystem(world)
-	u.UISystem(world)
+	for _, system := range pipeline.PreUpdate {
+		system.Run(world)
+		world.ApplyCommands()
+	}
+
+	// Run shadow update functions of paused states, from bottom to top of the stack
+	for iState, state := range sm.states[:len(sm.states)-1] {
+		if shadowState, ok := state.(ShadowUpdateState); ok {
+			withOwner(world, sm.owners[iState], shadowState.ShadowUpdate)
+		}
+	}
 
 	// Run state update function with game systems
 	sm.lastTransition = sm.states[len(sm.states)-1].Update(world)
+	world.ApplyCommands()
 
 	// Run post-game systems
-	a.AnimationSystem(world)
-	s.TransformSystem(world)
+	for _, system := range pipeline.PostUpdate {
+		system.Run(world)
+		world.ApplyCommands()
+	}
+
+	world.Resources.CurrentOwner = nil
+	return nil
 }
 
 // Draw draws the screen after a state update
 func (sm *StateMachine) Draw(world w.World, screen *ebiten.Image) {
+	switch {
+	case sm.effect != nil:
+		// Draw incoming scene on an offscreen image and compose it with the outgoing scene
+		if sm.effect.applied {
+			sm.toImage = offscreenImage(sm.toImage, screen)
+			sm.drawScene(world, sm.toImage)
+		}
+		if sm.fillImage == nil {
+			sm.fillImage = ebiten.NewImage(1, 1)
+			sm.fillImage.Fill(color.White)
+		}
+		sm.effect.draw(screen, sm.fromImage, sm.toImage, sm.fillImage)
+
+	case sm.lastTransition.hasEffect():
+		// Capture outgoing scene before starting the transition effect
+		sm.fromImage = offscreenImage(sm.fromImage, screen)
+		sm.drawScene(world, sm.fromImage)
+		screen.DrawImage(sm.fromImage, nil)
+		sm.captured = true
+
+	default:
+		sm.drawScene(world, screen)
+	}
+}
+
+// Draw the scene of the active state
+func (sm *StateMachine) drawScene(world w.World, screen *ebiten.Image) {
+	// Run shadow draw functions of paused states, from bottom to top of the stack
+	if len(sm.states) > 0 {
+		for iState, state := range sm.states[:len(sm.states)-1] {
+			if shadowState, ok := state.(ShadowDrawState); ok {
+				withOwner(world, sm.owners[iState], func(world w.World) { shadowState.ShadowDraw(world, screen) })
+			}
+		}
+	}
+
 	// Run drawing systems
-	s.RenderSpriteSystem(world, screen)
-	u.RenderUISystem(world, screen)
+	for _, system := range sm.activePipeline().Draw {
+		system.Run(world, screen)
+	}
+}
+
+// Get the pipeline of the active state
+func (sm *StateMachine) activePipeline() Pipeline {
+	if len(sm.states) > 0 {
+		if state, ok := sm.states[len(sm.states)-1].(PipelineState); ok {
+			return state.Pipeline(sm.Pipeline)
+		}
+	}
+	return sm.Pipeline
+}
+
+// Apply a transition to the state stack
+func (sm *StateMachine) applyTransition(world w.World, transition Transition) {
+	record := TransitionRecord{
+		Transition: transition,
+		OldStack:   append([]State{}, sm.states...),
+		NewStack:   sm.nextStack(transition),
+		Frame:      world.Resources.Time.Frame,
+	}
+
+	for _, observer := range sm.observers {
+		if observer.Before != nil {
+			observer.Before(world, record)
+		}
+	}
+
+	switch transition.Type {
+	case TransPop:
+		sm._Pop(world)
+	case TransPush:
+		sm._Push(world, transition.NewStates)
+	case TransSwitch:
+		sm._Switch(world, transition.NewStates)
+	case TransReplace:
+		sm._Replace(world, transition.NewStates)
+	case TransQuit:
+		sm._Quit(world)
+	}
+
+	record.NewStack = append([]State{}, sm.states...)
+	sm.recordTransition(record)
+
+	for _, observer := range sm.observers {
+		if observer.After != nil {
+			observer.After(world, record)
+		}
+	}
+}
+
+// Start a transition effect, using the outgoing scene captured during the last draw
+func (sm *StateMachine) startEffect(world w.World, transition Transition) {
+	sm.effect = &runningEffect{effect: transition.Effect, transition: transition, captured: sm.captured}
+	sm.captured = false
+	if sm.effect.applyTime() <= 0 {
+		sm.applyTransition(world, transition)
+		sm.effect.applied = true
+	}
+}
+
+// Advance the running transition effect
+func (sm *StateMachine) updateEffect(world w.World) {
+	sm.effect.elapsed += world.Resources.Time.UnscaledDelta
+	if !sm.effect.applied && sm.effect.elapsed >= sm.effect.applyTime() {
+		sm.applyTransition(world, sm.effect.transition)
+		sm.effect.applied = true
+	}
+	if sm.effect.elapsed >= sm.effect.effect.Duration {
+		sm.effect = nil
+	}
+}
+
+// Stop the running transition effect, applying its transition if needed
+func (sm *StateMachine) stopEffect(world w.World) {
+	if !sm.effect.applied {
+		sm.applyTransition(world, sm.effect.transition)
+	}
+	sm.effect = nil
 }
 
 // Remove the active state and resume the next state
 func (sm *StateMachine) _Pop(world w.World) {
-	sm.states[len(sm.states)-1].OnStop(world)
+	stopState(world, sm.owners[len(sm.owners)-1])
 	sm.states = sm.states[:len(sm.states)-1]
+	sm.owners = sm.owners[:len(sm.owners)-1]
 
-	if len(sm.states) > 0 {
-		sm.states[len(sm.states)-1].OnResume(world)
+	if len(sm.owners) > 0 {
+		resumeState(world, sm.owners[len(sm.owners)-1])
 	}
 }
 
 // Pause the active state and add new states to the stack
 func (sm *StateMachine) _Push(world w.World, newStates []State) {
 	if len(newStates) > 0 {
-		sm.states[len(sm.states)-1].OnPause(world)
+		pauseState(world, sm.owners[len(sm.owners)-1])
 
 		for _, state := range newStates[:len(newStates)-1] {
-			state.OnStart(world)
-			state.OnPause(world)
+			owner := sm.startState(world, state)
+			pauseState(world, owner)
+			sm.owners = append(sm.owners, owner)
 		}
-		newStates[len(newStates)-1].OnStart(world)
+		sm.owners = append(sm.owners, sm.startState(world, newStates[len(newStates)-1]))
 
 		sm.states = append(sm.states, newStates...)
 	}
@@ -129,28 +336,26 @@ func (sm *StateMachine) _Push(world w.World, newStates []State) {
 
 // Remove the active state and replace it by a new one
 func (sm *StateMachine) _Switch(world w.World, newStates []State) {
-	if len(newStates) != 1 {
-		utils.LogFatalf("switch transition accept only one new state")
-	}
-
-	sm.states[len(sm.states)-1].OnStop(world)
-	newStates[0].OnStart(world)
+	stopState(world, sm.owners[len(sm.owners)-1])
+	sm.owners[len(sm.owners)-1] = sm.startState(world, newStates[0])
 	sm.states[len(sm.states)-1] = newStates[0]
 }
 
 // Remove all states and insert a new stack
 func (sm *StateMachine) _Replace(world w.World, newStates []State) {
 	for len(sm.states) > 0 {
-		sm.states[len(sm.states)-1].OnStop(world)
+		stopState(world, sm.owners[len(sm.owners)-1])
 		sm.states = sm.states[:len(sm.states)-1]
+		sm.owners = sm.owners[:len(sm.owners)-1]
 	}
 
 	if len(newStates) > 0 {
 		for _, state := range newStates[:len(newStates)-1] {
-			state.OnStart(world)
-			state.OnPause(world)
+			owner := sm.startState(world, state)
+			pauseState(world, owner)
+			sm.owners = append(sm.owners, owner)
 		}
-		newStates[len(newStates)-1].OnStart(world)
+		sm.owners = append(sm.owners, sm.startState(world, newStates[len(newStates)-1]))
 	}
 	sm.states = newStates
 }
@@ -158,8 +363,17 @@ func (sm *StateMachine) _Replace(world w.World, newStates []State) {
 // Remove all states and quit
 func (sm *StateMachine) _Quit(world w.World) {
 	for len(sm.states) > 0 {
-		sm.states[len(sm.states)-1].OnStop(world)
+		stopState(world, sm.owners[len(sm.owners)-1])
 		sm.states = sm.states[:len(sm.states)-1]
+		sm.owners = sm.owners[:len(sm.owners)-1]
+	}
+}
+
+// Run the quit hook and return the termination error
+func (sm *StateMachine) quit(world w.World) error {
+	if sm.OnQuit != nil {
+		sm.OnQuit(world)
+		sm.OnQuit = nil
 	}
-	os.Exit(0)
+	return ErrQuit
 }
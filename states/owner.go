@@ -0,0 +1,52 @@
+package states
+
+import (
+	c "github.com/x-hgg-x/goecsengine/components"
+	"github.com/x-hgg-x/goecsengine/resources"
+	w "github.com/x-hgg-x/goecsengine/world"
+
+	ecs "github.com/x-hgg-x/goecs/v2"
+)
+
+// Execute a state function with the state set as owner of loaded entities, and apply queued commands
+func withOwner(world w.World, owner c.Owner, f func(world w.World)) {
+	previousOwner := world.Resources.CurrentOwner
+	world.Resources.CurrentOwner = &owner
+	f(world)
+	world.ApplyCommands()
+	world.Resources.CurrentOwner = previousOwner
+}
+
+// Start a state, and return its owner with a new unique ID
+func (sm *StateMachine) startState(world w.World, state State) c.Owner {
+	sm.lastOwnerID++
+	owner := c.Owner{State: state, ID: sm.lastOwnerID}
+	withOwner(world, owner, state.OnStart)
+	resources.Publish(world.Resources.Events, StatePushedEvent{State: state})
+	return owner
+}
+
+// Stop a state and delete the entities it owns
+func stopState(world w.World, owner c.Owner) {
+	state := owner.State.(State)
+	withOwner(world, owner, state.OnStop)
+	resources.Publish(world.Resources.Events, StatePoppedEvent{State: state})
+
+	ownedEntities := []ecs.Entity{}
+	world.Manager.Join(world.Components.Engine.Owner).Visit(ecs.Visit(func(entity ecs.Entity) {
+		if world.Components.Engine.Owner.Get(entity).(*c.Owner).ID == owner.ID {
+			ownedEntities = append(ownedEntities, entity)
+		}
+	}))
+	world.DeleteEntities(ownedEntities...)
+}
+
+// Pause a state
+func pauseState(world w.World, owner c.Owner) {
+	withOwner(world, owner, owner.State.(State).OnPause)
+}
+
+// Resume a state
+func resumeState(world w.World, owner c.Owner) {
+	withOwner(world, owner, owner.State.(State).OnResume)
+}
@@ -0,0 +1,123 @@
+package states
+
+import (
+	a "github.com/x-hgg-x/goecsengine/systems/animation"
+	h "github.com/x-hgg-x/goecsengine/systems/hierarchy"
+	i "github.com/x-hgg-x/goecsengine/systems/input"
+	s "github.com/x-hgg-x/goecsengine/systems/sprite"
+	u "github.com/x-hgg-x/goecsengine/systems/ui"
+	w "github.com/x-hgg-x/goecsengine/world"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Engine system names
+const (
+	InputSystemName        = "Input"
+	UISystemName           = "UI"
+	AnimationSystemName    = "Animation"
+	HierarchySystemName    = "Hierarchy"
+	TransformSystemName    = "Transform"
+	RenderSpriteSystemName = "RenderSprite"
+	RenderUISystemName     = "RenderUI"
+)
+
+// UpdateSystem is a named system executed on every update
+type UpdateSystem struct {
+	Name string
+	Run  func(world w.World)
+}
+
+// DrawSystem is a named system executed on every draw
+type DrawSystem struct {
+	Name string
+	Run  func(world w.World, screen *ebiten.Image)
+}
+
+// Pipeline contains ordered systems executed by the state machine
+type Pipeline struct {
+	// Systems executed before the state update
+	PreUpdate []UpdateSystem
+	// Systems executed after the state update
+	PostUpdate []UpdateSystem
+	// Systems executed when drawing the screen
+	Draw []DrawSystem
+}
+
+// PipelineState is implemented by states which use their own systems
+type PipelineState interface {
+	State
+	// Returns the pipeline used when the state is active, from the state machine pipeline
+	Pipeline(pipeline Pipeline) Pipeline
+}
+
+// DefaultPipeline returns a pipeline containing all engine systems
+func DefaultPipeline() Pipeline {
+	return Pipeline{
+		PreUpdate: []UpdateSystem{
+			{InputSystemName, i.InputSystem},
+			{UISystemName, u.UISystem},
+		},
+		PostUpdate: []UpdateSystem{
+			{AnimationSystemName, a.AnimationSystem},
+			{HierarchySystemName, h.HierarchySystem},
+			{TransformSystemName, s.TransformSystem},
+		},
+		Draw: []DrawSystem{
+			{RenderSpriteSystemName, s.RenderSpriteSystem},
+			{RenderUISystemName, u.RenderUISystem},
+		},
+	}
+}
+
+// Without returns a copy of the pipeline without the systems with the specified names
+func (p Pipeline) Without(names ...string) Pipeline {
+	excluded := make(map[string]bool, len(names))
+	for _, name := range names {
+		excluded[name] = true
+	}
+
+	filterUpdate := func(systems []UpdateSystem) []UpdateSystem {
+		filtered := []UpdateSystem{}
+		for _, system := range systems {
+			if !excluded[system.Name] {
+				filtered = append(filtered, system)
+			}
+		}
+		return filtered
+	}
+
+	draw := []DrawSystem{}
+	for _, system := range p.Draw {
+		if !excluded[system.Name] {
+			draw = append(draw, system)
+		}
+	}
+
+	return Pipeline{PreUpdate: filterUpdate(p.PreUpdate), PostUpdate: filterUpdate(p.PostUpdate), Draw: draw}
+}
+
+// ReplaceUpdate returns a copy of the pipeline where the update system with the specified name is replaced
+func (p Pipeline) ReplaceUpdate(name string, run func(world w.World)) Pipeline {
+	replace := func(systems []UpdateSystem) []UpdateSystem {
+		replaced := append([]UpdateSystem{}, systems...)
+		for iSystem := range replaced {
+			if replaced[iSystem].Name == name {
+				replaced[iSystem].Run = run
+			}
+		}
+		return replaced
+	}
+	return Pipeline{PreUpdate: replace(p.PreUpdate), PostUpdate: replace(p.PostUpdate), Draw: append([]DrawSystem{}, p.Draw...)}
+}
+
+// ReplaceDraw returns a copy of the pipeline where the draw system with the specified name is replaced
+func (p Pipeline) ReplaceDraw(name string, run func(world w.World, screen *ebiten.Image)) Pipeline {
+	draw := append([]DrawSystem{}, p.Draw...)
+	for iSystem := range draw {
+		if draw[iSystem].Name == name {
+			draw[iSystem].Run = run
+		}
+	}
+	return Pipeline{PreUpdate: append([]UpdateSystem{}, p.PreUpdate...), PostUpdate: append([]UpdateSystem{}, p.PostUpdate...), Draw: draw}
+}
@@ -5,10 +5,10 @@ import (
 
 	c "github.com/x-hgg-x/goecsengine/components"
 	m "github.com/x-hgg-x/goecsengine/math"
+	"github.com/x-hgg-x/goecsengine/resources"
 	"github.com/x-hgg-x/goecsengine/utils"
 	w "github.com/x-hgg-x/goecsengine/world"
 
-	"github.com/hajimehoshi/ebiten/v2"
 	ecs "github.com/x-hgg-x/goecs/v2"
 )
 
@@ -22,6 +22,7 @@ func AnimationSystem(world w.World) {
 		spriteNumbers := animationControl.Animation.SpriteNumber
 
 		currentTime := animationControl.GetState().CurrentTime
+		previousStateType := animationControl.GetState().Type
 
 		// Process command
 		switch animationControl.Command.Type {
@@ -57,7 +58,7 @@ func AnimationSystem(world w.World) {
 			animationControl.SetStateType(c.ControlStatePaused)
 
 		case c.AnimationCommandAbort:
-			entity.RemoveComponent(world.Components.Engine.AnimationControl)
+			world.Resources.Commands.RemoveComponent(entity, world.Components.Engine.AnimationControl)
 			return
 
 		case c.AnimationCommandNone:
@@ -72,7 +73,7 @@ func AnimationSystem(world w.World) {
 
 		// Run animation
 		if animationControl.GetState().Type == c.ControlStateRunning {
-			currentTime += animationControl.RateMultiplier / float64(ebiten.DefaultTPS)
+			currentTime += animationControl.RateMultiplier * world.Resources.Time.Delta
 		}
 
 		// Check animation end
@@ -91,9 +92,17 @@ func AnimationSystem(world w.World) {
 			}
 		}
 
+		// Publish event when animation is done
+		if animationControl.GetState().Type == c.ControlStateDone && previousStateType != c.ControlStateDone {
+			resources.Publish(world.Resources.Events, resources.AnimationDoneEvent{Entity: entity})
+		}
+
 		// Set animation state
 		animationControl.SetCurrentTime(currentTime)
-		sprite.SpriteNumber = spriteNumbers[computeAnimationPos(currentTime, times)]
+		if spriteNumber := spriteNumbers[computeAnimationPos(currentTime, times)]; sprite.SpriteNumber != spriteNumber {
+			sprite.SpriteNumber = spriteNumber
+			world.MarkChanged(entity, world.Components.Engine.SpriteRender)
+		}
 	}))
 }
 
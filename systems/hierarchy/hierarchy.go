@@ -0,0 +1,84 @@
+package hierarchysystem
+
+import (
+	c "github.com/x-hgg-x/goecsengine/components"
+	"github.com/x-hgg-x/goecsengine/resources"
+	w "github.com/x-hgg-x/goecsengine/world"
+
+	ecs "github.com/x-hgg-x/goecs/v2"
+)
+
+// HierarchySystem computes global transforms of child entities.
+// Entities in a hierarchy cycle are detached from their parent, and a HierarchyCycleEvent is published.
+func HierarchySystem(world w.World) {
+	parentComponent := world.Components.Engine.Parent
+	globalTransformComponent := world.Components.Engine.GlobalTransform
+
+	// Remove global transforms of entities without parent
+	staleEntities := []ecs.Entity{}
+	world.Manager.Join(globalTransformComponent, parentComponent.Not()).Visit(ecs.Visit(func(entity ecs.Entity) {
+		staleEntities = append(staleEntities, entity)
+	}))
+	for _, entity := range staleEntities {
+		world.RemoveComponent(entity, globalTransformComponent)
+		world.MarkChanged(entity, world.Components.Engine.Transform)
+	}
+
+	// Compute global transforms
+	computed := make(map[ecs.Entity]*c.Transform)
+	cycles := []ecs.Entity{}
+	world.Manager.Join(parentComponent, world.Components.Engine.Transform).Visit(ecs.Visit(func(entity ecs.Entity) {
+		globalTransform := computeGlobalTransform(world, entity, computed, map[ecs.Entity]bool{}, &cycles)
+		if !entity.HasComponent(globalTransformComponent) {
+			world.AddComponent(entity, globalTransformComponent, globalTransform)
+		} else if currentGlobalTransform := globalTransformComponent.Get(entity).(*c.Transform); *currentGlobalTransform != *globalTransform {
+			*currentGlobalTransform = *globalTransform
+			world.MarkChanged(entity, globalTransformComponent)
+		}
+	}))
+
+	// Break hierarchy cycles
+	for _, entity := range cycles {
+		if entity.HasComponent(parentComponent) {
+			world.RemoveComponent(entity, parentComponent)
+			resources.Publish(world.Resources.Events, resources.HierarchyCycleEvent{Entity: entity})
+		}
+	}
+}
+
+// GlobalTransform returns the global transform of an entity.
+// It is the GlobalTransform component for child entities and the Transform component otherwise.
+func GlobalTransform(world w.World, entity ecs.Entity) *c.Transform {
+	if entity.HasComponent(world.Components.Engine.GlobalTransform) {
+		return world.Components.Engine.GlobalTransform.Get(entity).(*c.Transform)
+	}
+	return world.Components.Engine.Transform.Get(entity).(*c.Transform)
+}
+
+// Compute the global transform of an entity.
+// An entity found twice in its ancestors is added to the cycles list, and is considered as a root entity.
+func computeGlobalTransform(world w.World, entity ecs.Entity, computed map[ecs.Entity]*c.Transform, visited map[ecs.Entity]bool, cycles *[]ecs.Entity) *c.Transform {
+	if globalTransform, ok := computed[entity]; ok {
+		return globalTransform
+	}
+
+	localTransform := c.NewTransform()
+	if entity.HasComponent(world.Components.Engine.Transform) {
+		localTransform = world.Components.Engine.Transform.Get(entity).(*c.Transform)
+	}
+
+	if visited[entity] {
+		*cycles = append(*cycles, entity)
+		return localTransform
+	}
+	visited[entity] = true
+
+	globalTransform := localTransform
+	if entity.HasComponent(world.Components.Engine.Parent) {
+		parent := world.Components.Engine.Parent.Get(entity).(*c.Parent).Entity
+		globalTransform = computeGlobalTransform(world, parent, computed, visited, cycles).Compose(localTransform)
+	}
+
+	computed[entity] = globalTransform
+	return globalTransform
+}
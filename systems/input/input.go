@@ -5,9 +5,6 @@ import (
 
 	"github.com/x-hgg-x/goecsengine/resources"
 	w "github.com/x-hgg-x/goecsengine/world"
-
-	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 )
 
 // InputSystem updates input axis values and actions
@@ -17,24 +14,25 @@ func InputSystem(world w.World) {
 	}
 
 	for k, v := range world.Resources.Controls.Actions {
-		world.Resources.InputHandler.Actions[k] = isActionDone(v)
+		world.Resources.InputHandler.Actions[k] = isActionDone(world.Resources.InputSource, v)
 	}
 }
 
 func getAxisValue(world w.World, axis resources.Axis) float64 {
+	input := world.Resources.InputSource
 	axisValue := 0.0
 
 	switch value := axis.Value.(type) {
 	case *resources.Emulated:
-		if isPressed(value.Pos) {
+		if isPressed(input, value.Pos) {
 			axisValue++
 		}
-		if isPressed(value.Neg) {
+		if isPressed(input, value.Neg) {
 			axisValue--
 		}
 	case *resources.ControllerAxis:
 		deadZone := math.Abs(value.DeadZone)
-		axisValue = ebiten.GamepadAxisValue(value.ID, value.Axis)
+		axisValue = input.GamepadAxisValue(value.ID, value.Axis)
 
 		if axisValue < -deadZone {
 			axisValue = (axisValue + deadZone) / (1.0 - deadZone)
@@ -51,7 +49,7 @@ func getAxisValue(world w.World, axis resources.Axis) float64 {
 		screenWidth := float64(world.Resources.ScreenDimensions.Width)
 		screenHeight := float64(world.Resources.ScreenDimensions.Height)
 
-		x, y := ebiten.CursorPosition()
+		x, y := input.CursorPosition()
 		switch value.Axis {
 		case 0:
 			axisValue = float64(x) / screenWidth
@@ -66,8 +64,8 @@ func getAxisValue(world w.World, axis resources.Axis) float64 {
 	return axisValue
 }
 
-func isActionDone(action resources.Action) bool {
-	var funcPressed func(resources.Button) bool
+func isActionDone(input resources.InputSource, action resources.Action) bool {
+	var funcPressed func(resources.InputSource, resources.Button) bool
 	if action.Once {
 		funcPressed = isJustPressed
 	} else {
@@ -77,7 +75,7 @@ func isActionDone(action resources.Action) bool {
 	for _, combination := range action.Combinations {
 		actionDone := true
 		for _, button := range combination {
-			actionDone = actionDone && funcPressed(button)
+			actionDone = actionDone && funcPressed(input, button)
 		}
 		if actionDone {
 			return true
@@ -86,26 +84,26 @@ func isActionDone(action resources.Action) bool {
 	return false
 }
 
-func isPressed(b resources.Button) bool {
+func isPressed(input resources.InputSource, b resources.Button) bool {
 	switch value := b.Value.(type) {
 	case *resources.Key:
-		return ebiten.IsKeyPressed(value.Key)
+		return input.IsKeyPressed(value.Key)
 	case *resources.MouseButton:
-		return ebiten.IsMouseButtonPressed(value.MouseButton)
+		return input.IsMouseButtonPressed(value.MouseButton)
 	case *resources.ControllerButton:
-		return ebiten.IsGamepadButtonPressed(value.ID, value.GamepadButton)
+		return input.IsGamepadButtonPressed(value.ID, value.GamepadButton)
 	}
 	return false
 }
 
-func isJustPressed(b resources.Button) bool {
+func isJustPressed(input resources.InputSource, b resources.Button) bool {
 	switch value := b.Value.(type) {
 	case *resources.Key:
-		return inpututil.IsKeyJustPressed(value.Key)
+		return input.IsKeyJustPressed(value.Key)
 	case *resources.MouseButton:
-		return inpututil.IsMouseButtonJustPressed(value.MouseButton)
+		return input.IsMouseButtonJustPressed(value.MouseButton)
 	case *resources.ControllerButton:
-		return inpututil.IsGamepadButtonJustPressed(value.ID, value.GamepadButton)
+		return input.IsGamepadButtonJustPressed(value.ID, value.GamepadButton)
 	}
 	return false
 }
@@ -7,6 +7,7 @@ import (
 
 	c "github.com/x-hgg-x/goecsengine/components"
 	m "github.com/x-hgg-x/goecsengine/math"
+	h "github.com/x-hgg-x/goecsengine/systems/hierarchy"
 	w "github.com/x-hgg-x/goecsengine/world"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -30,7 +31,7 @@ func RenderSpriteSystem(world w.World, screen *ebiten.Image) {
 	sprites.Visit(ecs.Visit(func(entity ecs.Entity) {
 		spritesDepths[iSprite] = spriteDepth{
 			sprite: world.Components.Engine.SpriteRender.Get(entity).(*c.SpriteRender),
-			depth:  world.Components.Engine.Transform.Get(entity).(*c.Transform).Depth,
+			depth:  h.GlobalTransform(world, entity).Depth,
 		}
 		iSprite++
 	}))
@@ -2,17 +2,26 @@ package spritesystem
 
 import (
 	c "github.com/x-hgg-x/goecsengine/components"
+	h "github.com/x-hgg-x/goecsengine/systems/hierarchy"
 	w "github.com/x-hgg-x/goecsengine/world"
-
-	ecs "github.com/x-hgg-x/goecs/v2"
 )
 
-// TransformSystem updates geometry matrix.
+// TransformSystem updates geometry matrix of entities with changed SpriteRender, Transform or GlobalTransform components,
+// and of all entities when the screen is resized.
 // Geometry matrix is first recentered, then scaled and rotated, and finally translated.
 func TransformSystem(world w.World) {
-	world.Manager.Join(world.Components.Engine.SpriteRender, world.Components.Engine.Transform).Visit(ecs.Visit(func(entity ecs.Entity) {
+	since := world.LastRun("TransformSystem")
+	if world.ScreenResizedSince(since) {
+		since = -1
+	}
+
+	changedEntities := world.Changed(since, world.Components.Engine.SpriteRender, world.Components.Engine.Transform, world.Components.Engine.GlobalTransform)
+	for _, entity := range changedEntities {
+		if !entity.HasComponent(world.Components.Engine.SpriteRender) || !entity.HasComponent(world.Components.Engine.Transform) {
+			continue
+		}
 		sprite := world.Components.Engine.SpriteRender.Get(entity).(*c.SpriteRender)
-		transform := world.Components.Engine.Transform.Get(entity).(*c.Transform)
+		transform := h.GlobalTransform(world, entity)
 
 		spriteWidth := float64(sprite.SpriteSheet.Sprites[sprite.SpriteNumber].Width)
 		spriteHeight := float64(sprite.SpriteSheet.Sprites[sprite.SpriteNumber].Height)
@@ -35,5 +44,5 @@ func TransformSystem(world w.World) {
 
 		offsetX, offsetY := transform.ComputeOriginOffset(screenWidth, screenHeight)
 		sprite.Options.GeoM.Translate(transform.Translation.X+offsetX, screenHeight-transform.Translation.Y-offsetY)
-	}))
+	}
 }
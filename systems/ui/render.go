@@ -10,14 +10,24 @@ import (
 	ecs "github.com/x-hgg-x/goecs/v2"
 )
 
-// RenderUISystem draws text entities
+// RenderUISystem draws text entities.
+// Dot offset is computed only for entities with changed Text or UITransform components.
 func RenderUISystem(world w.World, screen *ebiten.Image) {
+	changedEntities := world.Changed(world.LastRun("RenderUISystem"), world.Components.Engine.Text, world.Components.Engine.UITransform)
+	for _, entity := range changedEntities {
+		if entity.HasComponent(world.Components.Engine.Text) && entity.HasComponent(world.Components.Engine.UITransform) {
+			updateDotOffset(world, entity)
+		}
+	}
+
 	world.Manager.Join(world.Components.Engine.Text, world.Components.Engine.UITransform).Visit(ecs.Visit(func(entity ecs.Entity) {
 		textData := world.Components.Engine.Text.Get(entity).(*c.Text)
 		uiTransform := world.Components.Engine.UITransform.Get(entity).(*c.UITransform)
 
-		// Compute dot offset
-		x, y := utils.Try2(c.ComputeDotOffset(textData.Text, textData.FontFace, uiTransform.Pivot))
+		x, y, ok := uiTransform.GetDotOffset()
+		if !ok {
+			x, y = updateDotOffset(world, entity)
+		}
 
 		// Draw text
 		screenWidth := world.Resources.ScreenDimensions.Width
@@ -27,3 +37,13 @@ func RenderUISystem(world w.World, screen *ebiten.Image) {
 		text.Draw(screen, textData.Text, textData.FontFace, uiTransform.Translation.X+offsetX-x, screenHeight-uiTransform.Translation.Y-offsetY-y, textData.Color)
 	}))
 }
+
+// Compute and cache the dot offset of a text entity
+func updateDotOffset(world w.World, entity ecs.Entity) (x, y int) {
+	textData := world.Components.Engine.Text.Get(entity).(*c.Text)
+	uiTransform := world.Components.Engine.UITransform.Get(entity).(*c.UITransform)
+
+	x, y = utils.Try2(c.ComputeDotOffset(textData.Text, textData.FontFace, uiTransform.Pivot))
+	uiTransform.SetDotOffset(x, y)
+	return
+}
@@ -2,10 +2,11 @@ package uisystem
 
 import (
 	c "github.com/x-hgg-x/goecsengine/components"
+	"github.com/x-hgg-x/goecsengine/resources"
+	h "github.com/x-hgg-x/goecsengine/systems/hierarchy"
 	w "github.com/x-hgg-x/goecsengine/world"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	ecs "github.com/x-hgg-x/goecs/v2"
 )
 
@@ -13,7 +14,7 @@ import (
 func UISystem(world w.World) {
 	world.Manager.Join(world.Components.Engine.SpriteRender, world.Components.Engine.Transform, world.Components.Engine.MouseReactive).Visit(ecs.Visit(func(entity ecs.Entity) {
 		sprite := world.Components.Engine.SpriteRender.Get(entity).(*c.SpriteRender)
-		transform := world.Components.Engine.Transform.Get(entity).(*c.Transform)
+		transform := h.GlobalTransform(world, entity)
 		mouseReactive := world.Components.Engine.MouseReactive.Get(entity).(*c.MouseReactive)
 
 		screenWidth := float64(world.Resources.ScreenDimensions.Width)
@@ -29,9 +30,17 @@ func UISystem(world w.World) {
 		minY := screenHeight - (offsetY + transform.Translation.Y) - spriteHeight/2
 		maxY := screenHeight - (offsetY + transform.Translation.Y) + spriteHeight/2
 
-		x, y := ebiten.CursorPosition()
+		x, y := world.Resources.InputSource.CursorPosition()
 
-		mouseReactive.Hovered = minX <= float64(x) && float64(x) <= maxX && minY <= float64(y) && float64(y) <= maxY
-		mouseReactive.JustClicked = mouseReactive.Hovered && inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft)
+		hovered := minX <= float64(x) && float64(x) <= maxX && minY <= float64(y) && float64(y) <= maxY
+		if hovered != mouseReactive.Hovered {
+			resources.Publish(world.Resources.Events, resources.EntityHoveredEvent{Entity: entity, ID: mouseReactive.ID, Hovered: hovered})
+		}
+
+		mouseReactive.Hovered = hovered
+		mouseReactive.JustClicked = mouseReactive.Hovered && world.Resources.InputSource.IsMouseButtonJustPressed(ebiten.MouseButtonLeft)
+		if mouseReactive.JustClicked {
+			resources.Publish(world.Resources.Events, resources.EntityClickedEvent{Entity: entity, ID: mouseReactive.ID})
+		}
 	}))
 }
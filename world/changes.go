@@ -0,0 +1,170 @@
+package world
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/x-hgg-x/goecsengine/resources"
+
+	ecs "github.com/x-hgg-x/goecs/v2"
+)
+
+// Change ticks of components and systems
+type changeTracker struct {
+	tick    int
+	changed map[ecs.DataComponent]map[ecs.Entity]int
+	lastRun map[string]int
+	// Change tick of the last pruning
+	pruned int
+	// Last observed screen dimensions, with the change tick of their last modification
+	screenDimensions resources.ScreenDimensions
+	screenResized    int
+}
+
+func newChangeTracker() *changeTracker {
+	return &changeTracker{
+		changed: make(map[ecs.DataComponent]map[ecs.Entity]int),
+		lastRun: make(map[string]int),
+	}
+}
+
+// MarkChanged marks a component of an entity as changed.
+// Components modified through their pointers must be marked as changed for systems using change detection.
+func (world World) MarkChanged(entity ecs.Entity, component ecs.DataComponent) {
+	if world.changes == nil {
+		return
+	}
+	if world.changes.changed[component] == nil {
+		world.changes.changed[component] = make(map[ecs.Entity]int)
+	}
+	world.changes.changed[component][entity] = world.changes.tick
+}
+
+// GetMut returns the component data of an entity, and marks the component as changed
+func (world World) GetMut(entity ecs.Entity, component ecs.DataComponent) interface{} {
+	world.MarkChanged(entity, component)
+	return component.Get(entity)
+}
+
+// LastRun starts a new run of the system with the specified name, and returns the change tick of its previous run.
+// The returned change tick is negative if the system has never been run.
+func (world World) LastRun(systemName string) int {
+	if world.changes == nil {
+		return -1
+	}
+	lastRun, ok := world.changes.lastRun[systemName]
+	if !ok {
+		lastRun = -1
+	}
+	world.changes.prune()
+	world.changes.tick++
+	world.changes.lastRun[systemName] = world.changes.tick
+	return lastRun
+}
+
+// ChangedSince checks if a component of an entity has changed since the specified change tick.
+// All components are considered changed if the change tick is negative.
+func (world World) ChangedSince(entity ecs.Entity, component ecs.DataComponent, since int) bool {
+	if since < 0 || world.changes == nil {
+		return entity.HasComponent(component)
+	}
+	tick, ok := world.changes.changed[component][entity]
+	return ok && tick >= since && entity.HasComponent(component)
+}
+
+// Changed returns the entities with at least one of the specified components changed since the specified change tick, sorted by index.
+// All components are considered changed if the change tick is negative.
+func (world World) Changed(since int, components ...ecs.DataComponent) []ecs.Entity {
+	entitySet := make(map[ecs.Entity]bool)
+	for _, component := range components {
+		if since < 0 || world.changes == nil {
+			world.Manager.Join(component).Visit(ecs.Visit(func(entity ecs.Entity) {
+				entitySet[entity] = true
+			}))
+			continue
+		}
+		for entity := range world.changes.changed[component] {
+			if world.ChangedSince(entity, component, since) {
+				entitySet[entity] = true
+			}
+		}
+	}
+
+	entities := make([]ecs.Entity, 0, len(entitySet))
+	for entity := range entitySet {
+		entities = append(entities, entity)
+	}
+	sort.Slice(entities, func(i, j int) bool { return entities[i] < entities[j] })
+	return entities
+}
+
+// ScreenResizedSince checks if the screen dimensions have changed since the specified change tick.
+// The screen is considered resized if the change tick is negative.
+func (world World) ScreenResizedSince(since int) bool {
+	if world.changes == nil {
+		return true
+	}
+	screenDimensions := resources.ScreenDimensions{}
+	if world.Resources.ScreenDimensions != nil {
+		screenDimensions = *world.Resources.ScreenDimensions
+	}
+	if screenDimensions != world.changes.screenDimensions {
+		world.changes.screenDimensions = screenDimensions
+		world.changes.screenResized = world.changes.tick
+	}
+	return since < 0 || world.changes.screenResized >= since
+}
+
+// Remove change ticks older than the previous runs of all systems, since they cannot be queried anymore
+func (changes *changeTracker) prune() {
+	oldest := -1
+	for _, lastRun := range changes.lastRun {
+		if oldest < 0 || lastRun < oldest {
+			oldest = lastRun
+		}
+	}
+	if oldest <= changes.pruned {
+		return
+	}
+	changes.pruned = oldest
+
+	for _, changed := range changes.changed {
+		for entity, tick := range changed {
+			if tick < oldest {
+				delete(changed, entity)
+			}
+		}
+	}
+}
+
+// Mark all engine and registered game components of a new entity as changed
+func (world World) markEntityChanged(entity ecs.Entity) {
+	engine := reflect.ValueOf(world.Components.Engine).Elem()
+	for iField := 0; iField < engine.NumField(); iField++ {
+		if component, ok := engine.Field(iField).Interface().(ecs.DataComponent); ok && entity.HasComponent(component) {
+			world.MarkChanged(entity, component)
+		}
+	}
+	for _, name := range world.Components.RegisteredNames() {
+		registered, _ := world.Components.Registered(name)
+		if entity.HasComponent(registered.Component) {
+			world.MarkChanged(entity, registered.Component)
+		}
+	}
+}
+
+// Remove change ticks of a component of an entity
+func (world World) clearChanged(entity ecs.Entity, component ecs.DataComponent) {
+	if world.changes != nil {
+		delete(world.changes.changed[component], entity)
+	}
+}
+
+// Remove change ticks of all components of an entity
+func (world World) clearEntityChanged(entity ecs.Entity) {
+	if world.changes != nil {
+		for _, changed := range world.changes.changed {
+			delete(changed, entity)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package world
+
+import (
+	"github.com/x-hgg-x/goecsengine/resources"
+)
+
+// ApplyCommands applies queued commands in order.
+// Spawned entities are owned by the current state of the state machine if any.
+func (world World) ApplyCommands() {
+	if world.Resources.Commands == nil {
+		return
+	}
+
+	// Commands queued while applying commands are applied in the same call
+	for commands := world.Resources.Commands.Take(); len(commands) > 0; commands = world.Resources.Commands.Take() {
+		for _, command := range commands {
+			switch command.Type {
+			case resources.CommandSpawn:
+				if world.Resources.CurrentOwner != nil {
+					owner := *world.Resources.CurrentOwner
+					world.AddComponent(command.Entity, world.Components.Engine.Owner, &owner)
+				}
+			case resources.CommandDespawn:
+				world.DeleteEntity(command.Entity)
+			case resources.CommandAddComponent:
+				world.AddComponent(command.Entity, command.Component, command.Data)
+			case resources.CommandRemoveComponent:
+				world.RemoveComponent(command.Entity, command.Component)
+			}
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package world
+
+import (
+	c "github.com/x-hgg-x/goecsengine/components"
+	"github.com/x-hgg-x/goecsengine/utils"
+
+	ecs "github.com/x-hgg-x/goecs/v2"
+)
+
+// ComponentHooks contains functions executed when a component is added to or removed from an entity
+type ComponentHooks struct {
+	// Executed after the component is added to an entity
+	OnAdd func(world World, entity ecs.Entity)
+	// Executed before the component is removed from an entity or replaced, or before the entity is deleted.
+	// When the component is replaced, the new component data is returned by World.Replacement.
+	OnRemove func(world World, entity ecs.Entity)
+}
+
+// Component of an entity
+type entityComponent struct {
+	entity    ecs.Entity
+	component ecs.DataComponent
+}
+
+// Registered hooks, in registration order
+type componentHooks struct {
+	components []ecs.DataComponent
+	hooks      map[ecs.DataComponent][]ComponentHooks
+	onDelete   []func(world World, entity ecs.Entity)
+	// Data replacing components, while executing their OnRemove hooks
+	replacements map[entityComponent]interface{}
+}
+
+// RegisterHooks registers lifecycle hooks for a component.
+// Hooks are executed by the world methods and when loading entities.
+func (world World) RegisterHooks(component ecs.DataComponent, hooks ComponentHooks) {
+	if world.hooks == nil {
+		utils.LogFatalf("world is not initialized")
+	}
+	if _, ok := world.hooks.hooks[component]; !ok {
+		world.hooks.components = append(world.hooks.components, component)
+	}
+	world.hooks.hooks[component] = append(world.hooks.hooks[component], hooks)
+}
+
+// RegisterDeleteHook registers a function executed before an entity is deleted, after the OnRemove hooks of its components.
+// Delete hooks are executed by the world methods.
+func (world World) RegisterDeleteHook(onDelete func(world World, entity ecs.Entity)) {
+	if world.hooks == nil {
+		utils.LogFatalf("world is not initialized")
+	}
+	world.hooks.onDelete = append(world.hooks.onDelete, onDelete)
+}
+
+// Execute OnAdd hooks of a component
+func (world World) runAddHooks(entity ecs.Entity, component ecs.DataComponent) {
+	if world.hooks == nil {
+		return
+	}
+	for _, hooks := range world.hooks.hooks[component] {
+		if hooks.OnAdd != nil {
+			hooks.OnAdd(world, entity)
+		}
+	}
+}
+
+// Execute OnRemove hooks of a component
+func (world World) runRemoveHooks(entity ecs.Entity, component ecs.DataComponent) {
+	if world.hooks == nil {
+		return
+	}
+	for _, hooks := range world.hooks.hooks[component] {
+		if hooks.OnRemove != nil {
+			hooks.OnRemove(world, entity)
+		}
+	}
+}
+
+// Execute OnRemove hooks of a component replaced with new data
+func (world World) runReplaceHooks(entity ecs.Entity, component ecs.DataComponent, data interface{}) {
+	if world.hooks == nil {
+		return
+	}
+	key := entityComponent{entity, component}
+	world.hooks.replacements[key] = data
+	defer delete(world.hooks.replacements, key)
+	world.runRemoveHooks(entity, component)
+}
+
+// Replacement returns the data replacing a component of an entity, when called from an OnRemove hook executed by World.AddComponent
+func (world World) Replacement(entity ecs.Entity, component ecs.DataComponent) (interface{}, bool) {
+	if world.hooks == nil {
+		return nil, false
+	}
+	data, ok := world.hooks.replacements[entityComponent{entity, component}]
+	return data, ok
+}
+
+// EntityCreated indexes a new entity, marks its components as changed and executes the OnAdd hooks of its components.
+// It must be called after creating an entity with components added without using the World methods.
+func (world World) EntityCreated(entity ecs.Entity) {
+	world.IndexEntity(entity)
+	world.markEntityChanged(entity)
+	if world.hooks == nil {
+		return
+	}
+	for _, component := range world.hooks.components {
+		if entity.HasComponent(component) {
+			world.runAddHooks(entity, component)
+		}
+	}
+}
+
+// Execute the OnRemove hooks of all components of an entity, and the delete hooks
+func (world World) runEntityRemoveHooks(entity ecs.Entity) {
+	if world.hooks == nil {
+		return
+	}
+	for _, component := range world.hooks.components {
+		if entity.HasComponent(component) {
+			world.runRemoveHooks(entity, component)
+		}
+	}
+	for _, onDelete := range world.hooks.onDelete {
+		onDelete(world, entity)
+	}
+}
+
+func newComponentHooks() *componentHooks {
+	return &componentHooks{hooks: make(map[ecs.DataComponent][]ComponentHooks), replacements: make(map[entityComponent]interface{})}
+}
+
+// Register engine component hooks
+func registerEngineHooks(world World) {
+	world.RegisterHooks(world.Components.Engine.SpriteRender, ComponentHooks{OnRemove: disposeFillTexture})
+}
+
+// Dispose the texture image generated for a colored rectangle, unless it is still used by the replacing component
+func disposeFillTexture(world World, entity ecs.Entity) {
+	spriteSheet := world.Components.Engine.SpriteRender.Get(entity).(*c.SpriteRender).SpriteSheet
+	if spriteSheet == nil || spriteSheet.FillColor == nil || spriteSheet.Texture.Image == nil {
+		return
+	}
+	if replacement, ok := world.Replacement(entity, world.Components.Engine.SpriteRender); ok {
+		if spriteRender, ok := replacement.(*c.SpriteRender); ok && spriteRender.SpriteSheet != nil &&
+			(spriteRender.SpriteSheet == spriteSheet || spriteRender.SpriteSheet.Texture.Image == spriteSheet.Texture.Image) {
+			return
+		}
+	}
+	spriteSheet.Texture.Image.Dispose()
+}
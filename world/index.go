@@ -0,0 +1,105 @@
+package world
+
+import (
+	"sort"
+
+	c "github.com/x-hgg-x/goecsengine/components"
+
+	ecs "github.com/x-hgg-x/goecs/v2"
+)
+
+// Index of entities by name and tag.
+// Entries are verified when searching, so that deleted or renamed entities are never returned.
+type entityIndex struct {
+	names map[string]map[ecs.Entity]bool
+	tags  map[string]map[ecs.Entity]bool
+}
+
+func newEntityIndex() *entityIndex {
+	return &entityIndex{
+		names: make(map[string]map[ecs.Entity]bool),
+		tags:  make(map[string]map[ecs.Entity]bool),
+	}
+}
+
+func (index *entityIndex) add(entries map[string]map[ecs.Entity]bool, key string, entity ecs.Entity) {
+	if entries[key] == nil {
+		entries[key] = make(map[ecs.Entity]bool)
+	}
+	entries[key][entity] = true
+}
+
+// Search valid entities for a key, removing stale entries
+func (index *entityIndex) search(entries map[string]map[ecs.Entity]bool, key string, isValid func(entity ecs.Entity) bool) []ecs.Entity {
+	entities := []ecs.Entity{}
+	for entity := range entries[key] {
+		if isValid(entity) {
+			entities = append(entities, entity)
+		} else {
+			delete(entries[key], entity)
+		}
+	}
+	if len(entries[key]) == 0 {
+		delete(entries, key)
+	}
+	sort.Slice(entities, func(i, j int) bool { return entities[i] < entities[j] })
+	return entities
+}
+
+// IndexEntity adds the Name and Tags components of an entity to the world index.
+// It must be called after adding or modifying these components without using the World methods.
+func (world World) IndexEntity(entity ecs.Entity) {
+	if world.index == nil {
+		return
+	}
+	if entity.HasComponent(world.Components.Engine.Name) {
+		world.index.add(world.index.names, world.Components.Engine.Name.Get(entity).(*c.Name).Name, entity)
+	}
+	if entity.HasComponent(world.Components.Engine.Tags) {
+		for _, tag := range world.Components.Engine.Tags.Get(entity).(*c.Tags).Tags {
+			world.index.add(world.index.tags, tag, entity)
+		}
+	}
+}
+
+// FindByName returns the entity with the specified name.
+// If several entities have the same name, the entity with the lowest index is returned.
+func (world World) FindByName(name string) (ecs.Entity, bool) {
+	if world.index == nil {
+		return 0, false
+	}
+	entities := world.index.search(world.index.names, name, func(entity ecs.Entity) bool {
+		return entity.HasComponent(world.Components.Engine.Name) && world.Components.Engine.Name.Get(entity).(*c.Name).Name == name
+	})
+	if len(entities) == 0 {
+		return 0, false
+	}
+	return entities[0], true
+}
+
+// FindByTag returns the entities with the specified tag, sorted by index
+func (world World) FindByTag(tag string) []ecs.Entity {
+	if world.index == nil {
+		return []ecs.Entity{}
+	}
+	return world.index.search(world.index.tags, tag, func(entity ecs.Entity) bool {
+		return entity.HasComponent(world.Components.Engine.Tags) && world.Components.Engine.Tags.Get(entity).(*c.Tags).HasTag(tag)
+	})
+}
+
+// SetName sets the name of an entity
+func (world World) SetName(entity ecs.Entity, name string) {
+	world.AddComponent(entity, world.Components.Engine.Name, &c.Name{Name: name})
+}
+
+// AddTag adds a tag to an entity
+func (world World) AddTag(entity ecs.Entity, tag string) {
+	if !entity.HasComponent(world.Components.Engine.Tags) {
+		world.AddComponent(entity, world.Components.Engine.Tags, &c.Tags{})
+	}
+	tags := world.Components.Engine.Tags.Get(entity).(*c.Tags)
+	if !tags.HasTag(tag) {
+		tags.Tags = append(tags.Tags, tag)
+		world.IndexEntity(entity)
+	}
+}
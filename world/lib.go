@@ -12,17 +12,116 @@ type World struct {
 	Manager    *ecs.Manager
 	Components *c.Components
 	Resources  *resources.Resources
+
+	index   *entityIndex
+	hooks   *componentHooks
+	changes *changeTracker
 }
 
 // InitWorld initializes the world
 func InitWorld(gameComponents interface{}) World {
 	manager := ecs.NewManager()
 	components := c.InitComponents(manager, gameComponents)
+	commands := resources.NewCommands(manager)
 	resources := resources.InitResources()
+	resources.Commands = commands
 
-	return World{
+	world := World{
 		Manager:    manager,
 		Components: components,
 		Resources:  resources,
+		index:      newEntityIndex(),
+		hooks:      newComponentHooks(),
+		changes:    newChangeTracker(),
+	}
+	registerEngineHooks(world)
+	return world
+}
+
+// AddComponent adds a component to an entity, updating the world index and executing component hooks.
+// If the entity already has the component, it is replaced.
+func (world World) AddComponent(entity ecs.Entity, component ecs.DataComponent, data interface{}) ecs.Entity {
+	if entity.HasComponent(component) {
+		world.runReplaceHooks(entity, component, data)
+	}
+	entity.AddComponent(component, data)
+	world.MarkChanged(entity, component)
+	if component == ecs.DataComponent(world.Components.Engine.Name) || component == ecs.DataComponent(world.Components.Engine.Tags) {
+		world.IndexEntity(entity)
+	}
+	world.runAddHooks(entity, component)
+	return entity
+}
+
+// RemoveComponent removes a component from an entity, executing component hooks
+func (world World) RemoveComponent(entity ecs.Entity, component ecs.DataComponent) ecs.Entity {
+	if entity.HasComponent(component) {
+		world.runRemoveHooks(entity, component)
+		world.clearChanged(entity, component)
+		entity.RemoveComponent(component)
+	}
+	return entity
+}
+
+// DeleteEntity deletes an entity and its children, updating the world index and executing component hooks.
+// Entities already deleted (for example as children of a previously deleted entity) are ignored.
+func (world World) DeleteEntity(entity ecs.Entity) {
+	if !world.Manager.Join().Contains(int(entity)) {
+		return
+	}
+	children := world.children(entity)
+
+	world.runEntityRemoveHooks(entity)
+	if world.index != nil {
+		if entity.HasComponent(world.Components.Engine.Name) {
+			delete(world.index.names[world.Components.Engine.Name.Get(entity).(*c.Name).Name], entity)
+		}
+		if entity.HasComponent(world.Components.Engine.Tags) {
+			for _, tag := range world.Components.Engine.Tags.Get(entity).(*c.Tags).Tags {
+				delete(world.index.tags[tag], entity)
+			}
+		}
+	}
+	world.clearEntityChanged(entity)
+	world.Manager.DeleteEntity(entity)
+
+	// Children are deleted after their parent, so that entities in a hierarchy cycle are deleted only once
+	for _, child := range children {
+		world.DeleteEntity(child)
+	}
+}
+
+// Returns the entities with a Parent component referencing the specified entity
+func (world World) children(entity ecs.Entity) []ecs.Entity {
+	children := []ecs.Entity{}
+	world.Manager.Join(world.Components.Engine.Parent).Visit(ecs.Visit(func(child ecs.Entity) {
+		if world.Components.Engine.Parent.Get(child).(*c.Parent).Entity == entity {
+			children = append(children, child)
+		}
+	}))
+	return children
+}
+
+// DeleteEntities deletes entities and their children, updating the world index and executing component hooks
+func (world World) DeleteEntities(entities ...ecs.Entity) {
+	for _, entity := range entities {
+		world.DeleteEntity(entity)
+	}
+}
+
+// DeleteAllEntities deletes all entities, clears the world index, executes component hooks and discards queued commands
+func (world World) DeleteAllEntities() {
+	world.Manager.Join().Visit(ecs.Visit(func(entity ecs.Entity) {
+		world.runEntityRemoveHooks(entity)
+	}))
+	world.Manager.DeleteAllEntities()
+	if world.Resources.Commands != nil {
+		world.Resources.Commands.Take()
+	}
+	if world.index != nil {
+		*world.index = *newEntityIndex()
+	}
+	if world.changes != nil {
+		world.changes.changed = make(map[ecs.DataComponent]map[ecs.Entity]int)
 	}
 }